@@ -0,0 +1,192 @@
+package features
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/util/homedir"
+)
+
+const (
+	BackupDirName   = "k8c-backups"
+	BackupIndexFile = "index.json"
+	MaxBackups      = 20
+)
+
+type Backup struct {
+	ID      string    `json:"id"`
+	Path    string    `json:"path"`
+	File    string    `json:"file"`
+	Size    int64     `json:"size"`
+	Created time.Time `json:"created"`
+}
+
+func BackupDir() string {
+	return filepath.Join(homedir.HomeDir(), ".kube", BackupDirName)
+}
+
+func ListBackups() ([]Backup, error) {
+	data, err := os.ReadFile(filepath.Join(BackupDir(), BackupIndexFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var backups []Backup
+	if err := json.Unmarshal(data, &backups); err != nil {
+		return nil, fmt.Errorf("can't read backup index: %v", err)
+	}
+	return backups, nil
+}
+
+func saveBackupIndex(backups []Backup) error {
+	data, err := json.MarshalIndent(backups, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(BackupDir(), BackupIndexFile), data, 0600)
+}
+
+// BackupKubeconfig snapshots the file at path into the backup directory and
+// drops the oldest snapshots beyond MaxBackups. A missing file is not an error
+// since there is nothing to lose yet.
+func BackupKubeconfig(path string) (*Backup, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	dir := BackupDir()
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+
+	backups, err := ListBackups()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	id := now.Format("20060102-150405")
+	for i := 2; backupExists(backups, id); i++ {
+		id = fmt.Sprintf("%s-%d", now.Format("20060102-150405"), i)
+	}
+
+	backup := Backup{
+		ID:      id,
+		Path:    absPath,
+		File:    id + ".yaml",
+		Size:    int64(len(data)),
+		Created: now,
+	}
+	if err := os.WriteFile(filepath.Join(dir, backup.File), data, 0600); err != nil {
+		return nil, err
+	}
+	backups = append(backups, backup)
+
+	// Rotate the oldest backups out once over the retention limit
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].Created.Before(backups[j].Created)
+	})
+	for len(backups) > MaxBackups {
+		os.Remove(filepath.Join(dir, backups[0].File))
+		backups = backups[1:]
+	}
+
+	if err := saveBackupIndex(backups); err != nil {
+		return nil, err
+	}
+	return &backup, nil
+}
+
+func backupExists(backups []Backup, id string) bool {
+	for _, backup := range backups {
+		if backup.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func RestoreBackup(id string) error {
+	backups, err := ListBackups()
+	if err != nil {
+		return err
+	}
+
+	var backup *Backup
+	for i := range backups {
+		if backups[i].ID == id {
+			backup = &backups[i]
+			break
+		}
+	}
+	if backup == nil {
+		return fmt.Errorf("backup not found: %s", id)
+	}
+
+	data, err := os.ReadFile(filepath.Join(BackupDir(), backup.File))
+	if err != nil {
+		return err
+	}
+
+	if dryRun {
+		config, err := clientcmd.Load(data)
+		if err != nil {
+			return err
+		}
+		return PreviewKubeconfig(backup.Path, config)
+	}
+
+	// Snapshot the current file first so the restore itself can be undone
+	if _, err := BackupKubeconfig(backup.Path); err != nil {
+		return err
+	}
+	if err := os.WriteFile(backup.Path, data, 0600); err != nil {
+		return err
+	}
+
+	fmt.Printf("\n> Restored backup %s to: %s\n", backup.ID, backup.Path)
+	return nil
+}
+
+func ShowBackupList(backups []Backup) {
+	if len(backups) == 0 && !IsStructuredOutput() {
+		fmt.Println("No backups found in", BackupDir())
+		return
+	}
+
+	table := NewTable("backup",
+		Column{Header: "ID"},
+		Column{Header: "KUBECONFIG"},
+		Column{Header: "SIZE"},
+		Column{Header: "AGE"},
+		Column{Header: "FILE", Wide: true},
+	)
+	for i := len(backups) - 1; i >= 0; i-- {
+		backup := backups[i]
+		table.Append(backup.ID, backup,
+			backup.ID,
+			backup.Path,
+			ByteCountSI(backup.Size),
+			HumanReadableDuration(time.Since(backup.Created)),
+			backup.File,
+		)
+	}
+	RenderTable(table)
+}
@@ -2,21 +2,24 @@ package features
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/AlecAivazis/survey/v2"
-	"github.com/olekukonko/tablewriter"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/clientcmd"
 	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
-	"k8s.io/client-go/util/homedir"
 )
 
 type KubeConfig struct {
 	Files     []string
 	Merged    *clientcmdapi.Config
 	Overwrite bool
+	Strategy  MergeStrategy
+	Conflicts []MergeConflict
 }
 
 var (
@@ -24,6 +27,7 @@ var (
 	loadFile       string
 	selectedConfig string
 	configBytes    []byte
+	mergeStrategy  string
 	err            error
 )
 
@@ -36,7 +40,8 @@ func (kc *KubeConfig) Load() error {
 		}
 		configs = append(configs, loaded)
 	}
-	merged, err := MergeConfigs(configs)
+	merged, conflicts, err := MergeConfigsWithStrategy(configs, kc.Files, kc.Strategy)
+	kc.Conflicts = conflicts
 	if err != nil {
 		return err
 	}
@@ -45,41 +50,65 @@ func (kc *KubeConfig) Load() error {
 }
 
 func (kc *KubeConfig) SaveToFile(file string) error {
-	return clientcmd.WriteToFile(*kc.Merged, file)
+	return WriteKubeconfig(file, kc.Merged)
 }
 
-func MergeConfigs(configs []*clientcmdapi.Config) (*clientcmdapi.Config, error) {
-	newConfig := &clientcmdapi.Config{
-		Kind:       "Config",
-		APIVersion: "v1",
-		Clusters:   make(map[string]*clientcmdapi.Cluster),
-		AuthInfos:  make(map[string]*clientcmdapi.AuthInfo),
-		Contexts:   make(map[string]*clientcmdapi.Context),
-	}
+// SplitFile is the row of the split result, also used for its -o json/yaml
+// output.
+type SplitFile struct {
+	Context string `json:"context"`
+	File    string `json:"file"`
+}
 
-	for _, config := range configs {
-		for k, v := range config.AuthInfos {
-			newConfig.AuthInfos[k] = v
+// Split writes one self-contained kubeconfig per context of the loaded config
+// into outDir and returns the written files keyed by context name. Referenced
+// certificate and key files are embedded, so each file can be handed on as
+// is. Every context is checked before anything is written.
+func (kc *KubeConfig) Split(outDir string) (map[string]string, error) {
+	configs := make(map[string]*clientcmdapi.Config)
+	var broken []string
+	for _, contextName := range sortedKeys(kc.Merged.Contexts) {
+		config, err := ExtractContext(kc.Merged, contextName)
+		if err == nil {
+			err = clientcmdapi.FlattenConfig(config)
 		}
-		for k, v := range config.Clusters {
-			newConfig.Clusters[k] = v
+		if err != nil {
+			broken = append(broken, fmt.Sprintf("%s: %v", contextName, err))
+			continue
 		}
-		for k, v := range config.Contexts {
-			newConfig.Contexts[k] = v
+		configs[contextName] = config
+	}
+	if len(broken) > 0 {
+		return nil, fmt.Errorf("can't split, nothing written:\n  %s", strings.Join(broken, "\n  "))
+	}
+
+	if !dryRun {
+		if err := os.MkdirAll(outDir, 0700); err != nil {
+			return nil, err
 		}
 	}
-	for _, config := range configs {
-		if config.CurrentContext != "" {
-			newConfig.CurrentContext = config.CurrentContext
-			break
+
+	files := make(map[string]string)
+	used := make(map[string]bool)
+	for _, contextName := range sortedKeys(configs) {
+		name := uniqueName(SafeFileName(contextName), used)
+		used[name] = true
+		file := filepath.Join(outDir, name+".yaml")
+		if err := WriteKubeconfig(file, configs[contextName]); err != nil {
+			return files, err
 		}
+		files[contextName] = file
 	}
+	return files, nil
+}
 
-	return newConfig, nil
+func MergeConfigs(configs []*clientcmdapi.Config) (*clientcmdapi.Config, error) {
+	merged, _, err := MergeConfigsWithStrategy(configs, nil, MergeKeepLast)
+	return merged, err
 }
 
 func GetClientSet(kubeconfig string) (*kubernetes.Clientset, error) {
-	config, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
+	config, err := GetRestConfig(kubeconfig)
 	if err != nil {
 		return nil, err
 	}
@@ -92,6 +121,17 @@ func GetClientSet(kubeconfig string) (*kubernetes.Clientset, error) {
 	return clientset, nil
 }
 
+// GetDynamicClient is GetClientSet for resources without typed clients, such
+// as custom resources.
+func GetDynamicClient(kubeconfig string) (dynamic.Interface, error) {
+	config, err := GetRestConfig(kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return dynamic.NewForConfig(config)
+}
+
 func GetCurrentContext(config *clientcmdapi.Config) (string, error) {
 	if config == nil {
 		return "", fmt.Errorf("kubeconfig is nil")
@@ -122,43 +162,51 @@ func ListContexts(kc *KubeConfig) error {
 	return nil
 }
 
-func ShowDetailList(config *clientcmdapi.Config) error {
-	contextsMap := config.Contexts
+// ContextInfo is the row of the context list, also used for its -o json/yaml
+// output.
+type ContextInfo struct {
+	Name      string `json:"name"`
+	Cluster   string `json:"cluster"`
+	Server    string `json:"server"`
+	Namespace string `json:"namespace"`
+	User      string `json:"user"`
+	Current   bool   `json:"current"`
+}
 
-	// Create a slice of context information
-	var contextInfo []struct {
-		ContextName string
-		ClusterName string
-	}
+func ShowDetailList(config *clientcmdapi.Config) error {
+	table := NewTable("context",
+		Column{Header: "Context Name"},
+		Column{Header: "Cluster Name"},
+		Column{Header: "Namespace", Wide: true},
+		Column{Header: "User", Wide: true},
+	)
 
 	// Iterate through each context and extract the cluster and user information
-	for contextName, contextConfig := range contextsMap {
+	for _, contextName := range SortedContextNames(config) {
+		contextConfig := config.Contexts[contextName]
 		clusterName := contextConfig.Cluster
-		clusterConfig, found := config.Clusters[clusterName]
-		if !found {
-			return fmt.Errorf("cluster %s not found in config", clusterName)
+		info := ContextInfo{
+			Name:    contextName,
+			Cluster: clusterName,
+			User:    contextConfig.AuthInfo,
+			Current: contextName == config.CurrentContext,
 		}
-		contextInfo = append(contextInfo, struct {
-			ContextName string
-			ClusterName string
-		}{
-			ContextName: contextName,
-			ClusterName: clusterConfig.Server,
-		})
+		server := fmt.Sprintf("<cluster %s not found, run k8c doctor>", clusterName)
+		if clusterConfig, found := config.Clusters[clusterName]; found {
+			server = clusterConfig.Server
+			info.Server = server
+		}
+		namespace := contextConfig.Namespace
+		if namespace == "" {
+			namespace = DefaultNamespace
+		}
+		info.Namespace = namespace
+		table.Append(contextName, info, contextName, server, namespace, contextConfig.AuthInfo)
 	}
 
 	// Print the list of context names
-	fmt.Println("Available Kubernetes contexts:")
-
-	// Print the table of context information
-	table := tablewriter.NewWriter(os.Stdout)
-	table.Header([]string{"Context Name", "Cluster Name"})
-	for _, info := range contextInfo {
-		table.Append([]string{info.ContextName, info.ClusterName})
-	}
-	table.Render()
-
-	return nil
+	PrintHeading("Available Kubernetes contexts:\n")
+	return PrintTable(table)
 }
 
 func ShowContext(kc *KubeConfig) error {
@@ -177,28 +225,17 @@ func ShowContext(kc *KubeConfig) error {
 }
 
 func InitConfig() error {
-	home := homedir.HomeDir()
-	kubeconfig := filepath.Join(home, ".kube", "config")
-
-	// Use default kubeconfig file if load flag is not provided
-	if loadFile == "" {
-		if selectedConfig != "" {
-			configBytes, err = os.ReadFile(selectedConfig)
-		} else {
-			configBytes, err = os.ReadFile(kubeconfig)
-		}
-		if err != nil {
-			return err
-		}
-	} else {
-		// Load kubeconfig file from flag
-		configBytes, err = os.ReadFile(loadFile)
-		if err != nil {
-			return err
-		}
+	config, err := LoadKubeconfig(ActiveKubeconfig())
+	if err != nil {
+		return err
 	}
 
-	return nil
+	if clientcmdapi.IsConfigEmpty(config) {
+		configBytes = nil
+		return nil
+	}
+	configBytes, err = clientcmd.Write(*config)
+	return err
 }
 
 func SelectedConfig(contextNames []string, config *clientcmdapi.Config) error {
@@ -212,6 +249,10 @@ func SelectedConfig(contextNames []string, config *clientcmdapi.Config) error {
 		return err
 	}
 
+	return ApplyContext(selectedContext, config)
+}
+
+func ApplyContext(selectedContext string, config *clientcmdapi.Config) error {
 	fmt.Printf("Selected context: %s\n", selectedContext)
 
 	context, ok := config.Contexts[selectedContext]
@@ -233,45 +274,63 @@ func SelectedConfig(contextNames []string, config *clientcmdapi.Config) error {
 	// fmt.Printf("Cluster certificate authority: %s\n", cluster.CertificateAuthority)
 	// fmt.Printf("User name: %s\n", auth.Username)
 
-	if loadFile == "" {
-		ChangeKubeconfigContext(kubeconfig, selectedContext)
-	} else {
-		ChangeKubeconfigContext(loadFile, selectedContext)
-	}
-
-	return nil
+	return ChangeKubeconfigContext(os.Stdout, ActiveKubeconfig(), selectedContext)
 }
 
-func ChangeKubeconfigContext(kubeconfigPath string, contextName string) error {
-	// Load the Kubernetes configuration file.
-	kubeconfigBytes, err := os.ReadFile(kubeconfigPath)
-	if err != nil {
-		return err
-	}
-
-	// Parse the configuration file into an API object.
-	kubeconfig, err := clientcmd.Load(kubeconfigBytes)
+// ChangeKubeconfigContext makes contextName the current context and reports
+// the switch to out.
+func ChangeKubeconfigContext(out io.Writer, kubeconfigPath string, contextName string) error {
+	// Load the Kubernetes configuration the same way clientcmd will write it back.
+	pathOptions := PathOptions(kubeconfigPath)
+	kubeconfig, err := pathOptions.GetStartingConfig()
 	if err != nil {
-		fmt.Printf("\n> Can't read Kubernetes configuration file")
+		fmt.Fprintf(out, "\n> Can't read Kubernetes configuration file")
 		return err
 	}
 
 	// Check if the specified context exists.
 	if _, ok := kubeconfig.Contexts[contextName]; !ok {
-		fmt.Printf("\n> Context does not exist in the Kubernetes configuration file ($HOME/.kube/config) \n> Merge into your Kubernetes config file first... ")
-		return err
+		fmt.Fprintf(out, "\n> Context does not exist in the Kubernetes configuration (%s) \n> Merge into your Kubernetes config file first... ", strings.Join(pathOptions.GetLoadingPrecedence(), ", "))
+		return fmt.Errorf("context not found: %s", contextName)
 	}
 
+	// current-context is always stored in the default file of the chain.
+	target := pathOptions.GetDefaultFilename()
+
 	// Change the current context to the new context.
+	previousContext := kubeconfig.CurrentContext
 	kubeconfig.CurrentContext = contextName
 
+	if dryRun {
+		return PreviewFileChange(target, func(config *clientcmdapi.Config) {
+			config.CurrentContext = contextName
+		})
+	}
+
 	// Write the modified configuration back to the file.
-	err = clientcmd.ModifyConfig(clientcmd.NewDefaultPathOptions(), *kubeconfig, true)
+	err = ModifyKubeconfig(pathOptions, kubeconfig, target)
 	if err != nil {
-		fmt.Printf("\n> Failed to change context: %s\n", kubeconfig.CurrentContext)
+		fmt.Fprintf(out, "\n> Failed to change context: %s\n", kubeconfig.CurrentContext)
 		return err
 	} else {
-		fmt.Printf("\n> Successfully change context to: %s\n", kubeconfig.CurrentContext)
+		fmt.Fprintf(out, "\n> Successfully change context to: %s\n", kubeconfig.CurrentContext)
+		if previousContext != contextName {
+			if err := RecordSwitch(target, previousContext, contextName); err != nil {
+				fmt.Fprintf(out, "> Can't record context history: %v\n", err)
+			}
+		}
 		return nil
 	}
 }
+
+// ModifyKubeconfig writes config back through clientcmd, which puts every
+// entry into the file it belongs to. The files about to change are backed up
+// first.
+func ModifyKubeconfig(pathOptions *clientcmd.PathOptions, config *clientcmdapi.Config, files ...string) error {
+	for _, file := range files {
+		if _, err := BackupKubeconfig(file); err != nil {
+			return fmt.Errorf("can't back up %s: %v", file, err)
+		}
+	}
+	return clientcmd.ModifyConfig(pathOptions, *config, true)
+}
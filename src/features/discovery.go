@@ -0,0 +1,181 @@
+package features
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+)
+
+// APIResource is a resource served by the cluster together with the group
+// version it was discovered in.
+type APIResource struct {
+	metav1.APIResource
+	GroupVersion schema.GroupVersion
+}
+
+func (r APIResource) GroupVersionResource() schema.GroupVersionResource {
+	return r.GroupVersion.WithResource(r.Name)
+}
+
+// FullName is the resource name qualified with its group, e.g.
+// certificates.cert-manager.io, or just the name for the core group.
+func (r APIResource) FullName() string {
+	if r.GroupVersion.Group == "" {
+		return r.Name
+	}
+	return r.Name + "." + r.GroupVersion.Group
+}
+
+// ServerResources returns the resources of the preferred version of every API
+// group, without subresources. Groups that fail discovery (e.g. an aggregated
+// API that is down) are skipped as long as the rest can be listed.
+func ServerResources(client discovery.DiscoveryInterface) ([]APIResource, error) {
+	lists, err := client.ServerPreferredResources()
+	if err != nil && len(lists) == 0 {
+		return nil, err
+	}
+	return flattenResources(lists), nil
+}
+
+func flattenResources(lists []*metav1.APIResourceList) []APIResource {
+	var resources []APIResource
+	for _, list := range lists {
+		if list == nil {
+			continue
+		}
+		groupVersion, err := schema.ParseGroupVersion(list.GroupVersion)
+		if err != nil {
+			continue
+		}
+		for _, resource := range list.APIResources {
+			if strings.Contains(resource.Name, "/") {
+				continue
+			}
+			resources = append(resources, APIResource{APIResource: resource, GroupVersion: groupVersion})
+		}
+	}
+	sort.SliceStable(resources, func(i, j int) bool {
+		if resources[i].GroupVersion.Group != resources[j].GroupVersion.Group {
+			return resources[i].GroupVersion.Group < resources[j].GroupVersion.Group
+		}
+		return resources[i].Name < resources[j].Name
+	})
+	return resources
+}
+
+// ResolveResource finds the resource a user typed: a plural, singular or
+// short name or kind, optionally qualified with the group (nodepools.karpenter.sh),
+// or a group/version/kind (cert-manager.io/v1/Certificate, v1/Pod for core).
+func ResolveResource(client discovery.DiscoveryInterface, name string) (APIResource, error) {
+	if parts := strings.Split(name, "/"); len(parts) > 1 {
+		return resolveGroupVersionKind(client, parts)
+	}
+
+	resources, err := ServerResources(client)
+	if err != nil {
+		return APIResource{}, err
+	}
+	return findResource(resources, name)
+}
+
+func findResource(resources []APIResource, name string) (APIResource, error) {
+	resourceName, group, _ := strings.Cut(name, ".")
+	var matches []APIResource
+	for _, resource := range resources {
+		if group != "" && resource.GroupVersion.Group != group {
+			continue
+		}
+		if matchesResourceName(resource, resourceName) {
+			matches = append(matches, resource)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return APIResource{}, fmt.Errorf("unknown resource type: %s", name)
+	case 1:
+		return matches[0], nil
+	}
+
+	// Like kubectl, the core group wins over a CRD reusing a built-in name
+	var candidates []string
+	for _, match := range matches {
+		if match.GroupVersion.Group == "" {
+			return match, nil
+		}
+		candidates = append(candidates, match.FullName())
+	}
+	return APIResource{}, fmt.Errorf("resource type %s is ambiguous, use one of: %s", name, strings.Join(candidates, ", "))
+}
+
+func resolveGroupVersionKind(client discovery.DiscoveryInterface, parts []string) (APIResource, error) {
+	var gvk schema.GroupVersionKind
+	switch len(parts) {
+	case 2:
+		gvk = schema.GroupVersionKind{Version: parts[0], Kind: parts[1]}
+	case 3:
+		gvk = schema.GroupVersionKind{Group: parts[0], Version: parts[1], Kind: parts[2]}
+	default:
+		return APIResource{}, fmt.Errorf("invalid resource type %s, expected group/version/kind", strings.Join(parts, "/"))
+	}
+
+	list, err := client.ServerResourcesForGroupVersion(gvk.GroupVersion().String())
+	if err != nil {
+		return APIResource{}, err
+	}
+	for _, resource := range flattenResources([]*metav1.APIResourceList{list}) {
+		if strings.EqualFold(resource.Kind, gvk.Kind) || matchesResourceName(resource, strings.ToLower(gvk.Kind)) {
+			return resource, nil
+		}
+	}
+	return APIResource{}, fmt.Errorf("unknown resource type: %s", strings.Join(parts, "/"))
+}
+
+func matchesResourceName(resource APIResource, name string) bool {
+	name = strings.ToLower(name)
+	if resource.Name == name || resource.SingularName == name || strings.ToLower(resource.Kind) == name {
+		return true
+	}
+	for _, shortName := range resource.ShortNames {
+		if shortName == name {
+			return true
+		}
+	}
+	return false
+}
+
+func ShowAPIResources(resources []APIResource, group string) {
+	table := NewTable("apiresource",
+		Column{Header: "NAME"},
+		Column{Header: "SHORTNAMES"},
+		Column{Header: "APIVERSION"},
+		Column{Header: "NAMESPACED"},
+		Column{Header: "KIND"},
+		Column{Header: "VERBS"},
+		Column{Header: "CATEGORIES", Wide: true},
+	)
+
+	for _, resource := range resources {
+		if group != "" && resource.GroupVersion.Group != group {
+			continue
+		}
+		info := resource.APIResource
+		info.Group = resource.GroupVersion.Group
+		info.Version = resource.GroupVersion.Version
+
+		table.Append(resource.FullName(), info,
+			resource.Name,
+			strings.Join(resource.ShortNames, ","),
+			resource.GroupVersion.String(),
+			fmt.Sprintf("%t", resource.Namespaced),
+			resource.Kind,
+			strings.Join(resource.Verbs, ","),
+			strings.Join(resource.Categories, ","),
+		)
+	}
+	RenderTable(table)
+}
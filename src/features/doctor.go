@@ -0,0 +1,240 @@
+package features
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+const (
+	SeverityError   = "ERROR"
+	SeverityWarning = "WARNING"
+	SeverityInfo    = "INFO"
+
+	CertExpiryWarning = 30 * 24 * time.Hour
+)
+
+type Finding struct {
+	Severity string
+	Kind     string
+	Name     string
+	Message  string
+	Fix      func(config *clientcmdapi.Config)
+}
+
+func Diagnose(config *clientcmdapi.Config) []Finding {
+	var findings []Finding
+
+	// Relative paths point next to the file that defines the entry, not into
+	// the working directory
+	config = config.DeepCopy()
+	if err := clientcmd.ResolveLocalPaths(config); err != nil {
+		findings = append(findings, Finding{Severity: SeverityError, Kind: "kubeconfig", Name: "-", Message: err.Error()})
+	}
+
+	for _, name := range sortedKeys(config.Contexts) {
+		context := config.Contexts[name]
+		if _, ok := config.Clusters[context.Cluster]; !ok {
+			findings = append(findings, Finding{Severity: SeverityError, Kind: "context", Name: name, Message: fmt.Sprintf("cluster %q not found", context.Cluster)})
+		}
+		if _, ok := config.AuthInfos[context.AuthInfo]; !ok && context.AuthInfo != "" {
+			findings = append(findings, Finding{Severity: SeverityError, Kind: "context", Name: name, Message: fmt.Sprintf("user %q not found", context.AuthInfo)})
+		}
+	}
+
+	servers := make(map[string][]string)
+	for _, name := range sortedKeys(config.Clusters) {
+		cluster := config.Clusters[name]
+		if cluster.Server == "" {
+			findings = append(findings, Finding{Severity: SeverityError, Kind: "cluster", Name: name, Message: "no server URL"})
+		} else {
+			servers[cluster.Server] = append(servers[cluster.Server], name)
+		}
+		findings = append(findings, checkFile("cluster", name, "certificate-authority", cluster.CertificateAuthority)...)
+	}
+	for _, server := range sortedKeys(servers) {
+		if names := servers[server]; len(names) > 1 {
+			findings = append(findings, Finding{Severity: SeverityWarning, Kind: "cluster", Name: strings.Join(names, ", "), Message: "duplicate server URL " + server})
+		}
+	}
+
+	for _, name := range sortedKeys(config.AuthInfos) {
+		authInfo := config.AuthInfos[name]
+		findings = append(findings, checkFile("user", name, "client-certificate", authInfo.ClientCertificate)...)
+		findings = append(findings, checkFile("user", name, "client-key", authInfo.ClientKey)...)
+		findings = append(findings, checkFile("user", name, "token-file", authInfo.TokenFile)...)
+
+		certData := authInfo.ClientCertificateData
+		if len(certData) == 0 && authInfo.ClientCertificate != "" {
+			certData, _ = os.ReadFile(authInfo.ClientCertificate)
+		}
+		if len(certData) > 0 {
+			findings = append(findings, checkCertificate(name, certData)...)
+		}
+
+		if authInfo.Exec != nil && authInfo.Exec.Command != "" {
+			if _, err := exec.LookPath(authInfo.Exec.Command); err != nil {
+				findings = append(findings, Finding{Severity: SeverityError, Kind: "user", Name: name, Message: fmt.Sprintf("exec plugin %q not found in PATH", authInfo.Exec.Command)})
+			}
+		}
+	}
+
+	clusters, authInfos := OrphanedEntries(config)
+	for _, name := range clusters {
+		cluster := name
+		findings = append(findings, Finding{Severity: SeverityInfo, Kind: "cluster", Name: name, Message: "not referenced by any context", Fix: func(config *clientcmdapi.Config) {
+			delete(config.Clusters, cluster)
+		}})
+	}
+	for _, name := range authInfos {
+		authInfo := name
+		findings = append(findings, Finding{Severity: SeverityInfo, Kind: "user", Name: name, Message: "not referenced by any context", Fix: func(config *clientcmdapi.Config) {
+			delete(config.AuthInfos, authInfo)
+		}})
+	}
+
+	if config.CurrentContext == "" {
+		findings = append(findings, Finding{Severity: SeverityWarning, Kind: "current-context", Name: "-", Message: "current-context is not set"})
+	} else if _, ok := config.Contexts[config.CurrentContext]; !ok {
+		dangling := config.CurrentContext
+		findings = append(findings, Finding{Severity: SeverityError, Kind: "current-context", Name: dangling, Message: "points to a context that does not exist", Fix: func(config *clientcmdapi.Config) {
+			if config.CurrentContext == dangling {
+				config.CurrentContext = ""
+			}
+		}})
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		return severityRank(findings[i].Severity) < severityRank(findings[j].Severity)
+	})
+	return findings
+}
+
+func checkFile(kind string, name string, field string, path string) []Finding {
+	if path == "" {
+		return nil
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return []Finding{{Severity: SeverityError, Kind: kind, Name: name, Message: fmt.Sprintf("%s %s is not readable: %v", field, path, err)}}
+	}
+	file.Close()
+	return nil
+}
+
+func checkCertificate(name string, data []byte) []Finding {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return []Finding{{Severity: SeverityError, Kind: "user", Name: name, Message: "client certificate is not valid PEM"}}
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return []Finding{{Severity: SeverityError, Kind: "user", Name: name, Message: fmt.Sprintf("can't parse client certificate: %v", err)}}
+	}
+
+	remaining := time.Until(cert.NotAfter)
+	switch {
+	case remaining <= 0:
+		return []Finding{{Severity: SeverityError, Kind: "user", Name: name, Message: fmt.Sprintf("client certificate expired on %s", cert.NotAfter.Format("2006-01-02"))}}
+	case remaining < CertExpiryWarning:
+		return []Finding{{Severity: SeverityWarning, Kind: "user", Name: name, Message: fmt.Sprintf("client certificate expires in %s", HumanReadableDuration(remaining))}}
+	}
+	return nil
+}
+
+func severityRank(severity string) int {
+	switch severity {
+	case SeverityError:
+		return 0
+	case SeverityWarning:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// FindingInfo is the row of the doctor report, also used for its -o
+// json/yaml output.
+type FindingInfo struct {
+	Severity string `json:"severity"`
+	Kind     string `json:"kind"`
+	Name     string `json:"name"`
+	Message  string `json:"message"`
+	Fixable  bool   `json:"fixable"`
+}
+
+func ShowFindings(findings []Finding) error {
+	if len(findings) == 0 && !IsStructuredOutput() {
+		fmt.Println("No problems found")
+		return nil
+	}
+
+	table := NewTable("finding",
+		Column{Header: "Severity"},
+		Column{Header: "Kind"},
+		Column{Header: "Name"},
+		Column{Header: "Finding"},
+		Column{Header: "Fixable"},
+	)
+	for _, finding := range findings {
+		info := FindingInfo{Severity: finding.Severity, Kind: finding.Kind, Name: finding.Name, Message: finding.Message, Fixable: finding.Fix != nil}
+		fixable := "no"
+		if info.Fixable {
+			fixable = "yes"
+		}
+		table.Append(finding.Name, info, finding.Severity, finding.Kind, finding.Name, finding.Message, fixable)
+	}
+	return PrintTable(table)
+}
+
+func RunDoctor(kubeconfigPath string, fix bool) error {
+	config, err := LoadKubeconfig(kubeconfigPath)
+	if err != nil {
+		return err
+	}
+
+	findings := Diagnose(config)
+	if err := ShowFindings(findings); err != nil {
+		return err
+	}
+
+	var fixes []func(config *clientcmdapi.Config)
+	unresolved := 0
+	for _, finding := range findings {
+		if finding.Fix != nil {
+			fixes = append(fixes, finding.Fix)
+		}
+		if finding.Severity == SeverityError && (finding.Fix == nil || !fix) {
+			unresolved++
+		}
+	}
+
+	if fix && len(fixes) > 0 {
+		changed, err := EditKubeconfig(kubeconfigPath, func(config *clientcmdapi.Config) {
+			for _, apply := range fixes {
+				apply(config)
+			}
+		})
+		if err != nil {
+			return err
+		}
+		if !dryRun {
+			fmt.Printf("\n> Applied %d fix(es) to: %s\n", len(fixes), strings.Join(changed, ", "))
+		}
+	} else if len(fixes) > 0 {
+		PrintHeading("> %d finding(s) can be repaired with --fix\n", len(fixes))
+	}
+
+	if unresolved > 0 {
+		return fmt.Errorf("found %d error(s) in kubeconfig", unresolved)
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package features
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+)
+
+const GatewayGroup = "gateway.networking.k8s.io"
+
+// httpRoute is the part of a Gateway API HTTPRoute the table shows. Routes are
+// read through the dynamic client, so the Gateway API module isn't needed.
+type httpRoute struct {
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              struct {
+		ParentRefs []struct {
+			Name        string  `json:"name"`
+			Namespace   *string `json:"namespace"`
+			SectionName *string `json:"sectionName"`
+		} `json:"parentRefs"`
+		Hostnames []string `json:"hostnames"`
+		Rules     []struct {
+			Matches []struct {
+				Path *struct {
+					Type  *string `json:"type"`
+					Value *string `json:"value"`
+				} `json:"path"`
+			} `json:"matches"`
+			BackendRefs []struct {
+				Name      string  `json:"name"`
+				Namespace *string `json:"namespace"`
+				Port      *int32  `json:"port"`
+				Weight    *int32  `json:"weight"`
+			} `json:"backendRefs"`
+		} `json:"rules"`
+	} `json:"spec"`
+}
+
+// HTTPRouteResource returns the HTTPRoute resource in the newest Gateway API
+// version the server serves, or an error if the CRDs are not installed.
+func HTTPRouteResource(clientset kubernetes.Interface) (schema.GroupVersionResource, error) {
+	for _, version := range []string{"v1", "v1beta1"} {
+		groupVersion := schema.GroupVersion{Group: GatewayGroup, Version: version}
+		resources, err := clientset.Discovery().ServerResourcesForGroupVersion(groupVersion.String())
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return schema.GroupVersionResource{}, err
+		}
+		for _, resource := range resources.APIResources {
+			if resource.Name == "httproutes" {
+				return groupVersion.WithResource("httproutes"), nil
+			}
+		}
+	}
+	return schema.GroupVersionResource{}, fmt.Errorf("httproutes not available, the Gateway API CRDs (%s) are not installed", GatewayGroup)
+}
+
+func HTTPRouteTable(routes *unstructured.UnstructuredList) *Table {
+	table := NewTable("httproute."+GatewayGroup,
+		Column{Header: "NAME"},
+		Column{Header: "HOSTNAMES"},
+		Column{Header: "PARENTS"},
+		Column{Header: "RULES"},
+		Column{Header: "AGE"},
+	)
+
+	for i := range routes.Items {
+		item := &routes.Items[i]
+		var route httpRoute
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(item.Object, &route); err != nil {
+			fmt.Printf("Error reading httproute %s: %v\n", item.GetName(), err)
+			continue
+		}
+
+		hostnames := route.Spec.Hostnames
+		if len(hostnames) == 0 {
+			hostnames = []string{"*"}
+		}
+
+		var parents []string
+		for _, parent := range route.Spec.ParentRefs {
+			name := parent.Name
+			if parent.Namespace != nil {
+				name = *parent.Namespace + "/" + name
+			}
+			if parent.SectionName != nil {
+				name += "." + *parent.SectionName
+			}
+			parents = append(parents, name)
+		}
+
+		var rules []string
+		for _, rule := range route.Spec.Rules {
+			var paths []string
+			for _, match := range rule.Matches {
+				if match.Path != nil && match.Path.Value != nil {
+					paths = append(paths, *match.Path.Value)
+				}
+			}
+			if len(paths) == 0 {
+				paths = []string{"/"}
+			}
+
+			var backends []string
+			for _, backend := range rule.BackendRefs {
+				name := backend.Name
+				if backend.Namespace != nil {
+					name = *backend.Namespace + "/" + name
+				}
+				if backend.Port != nil {
+					name += ":" + strconv.Itoa(int(*backend.Port))
+				}
+				if backend.Weight != nil && len(rule.BackendRefs) > 1 {
+					name += fmt.Sprintf(" (%d)", *backend.Weight)
+				}
+				backends = append(backends, name)
+			}
+			if len(backends) == 0 {
+				backends = []string{"<none>"}
+			}
+			rules = append(rules, fmt.Sprintf("%s -> %s", strings.Join(paths, ", "), strings.Join(backends, ", ")))
+		}
+
+		table.Append(route.Name, item,
+			route.Name,
+			strings.Join(hostnames, ", "),
+			strings.Join(parents, ", "),
+			strings.Join(rules, "\n"),
+			HumanReadableDuration(time.Since(route.CreationTimestamp.Time)),
+		)
+	}
+	return table
+}
@@ -0,0 +1,283 @@
+package features
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// Asking for the Table format makes the server render the columns kubectl
+// shows, including the additional printer columns of CRDs.
+const tableAcceptHeader = "application/json;as=Table;v=v1;g=meta.k8s.io,application/json;as=Table;v=v1beta1;g=meta.k8s.io,application/json"
+
+// ListGenericResources lists any resource found through discovery and hands
+// emit a table per page. Tables come from the server, structured output
+// lists the full objects through the dynamic client.
+func ListGenericResources(ctx context.Context, clientset kubernetes.Interface, resource APIResource, namespace string, options metav1.ListOptions, emit func(*Table) error) error {
+	if IsStructuredOutput() {
+		client, err := GetDynamicClient(ActiveKubeconfig())
+		if err != nil {
+			return err
+		}
+		namespaceable := client.Resource(resource.GroupVersionResource())
+		var resourceClient dynamic.ResourceInterface = namespaceable
+		if resource.Namespaced {
+			resourceClient = namespaceable.Namespace(namespace)
+		}
+		return listAndWatch(ctx, resourceClient, options, func(items *unstructured.UnstructuredList) *Table {
+			table := NewTable(resource.FullName())
+			for i := range items.Items {
+				item := &items.Items[i]
+				table.Append(item.GetName(), item)
+			}
+			return table
+		}, emit)
+	}
+
+	tables := &serverTables{clientset: clientset, resource: resource, namespace: namespace, options: options}
+	resourceVersion := ""
+	for {
+		request := tables.request()
+		if options.Limit > 0 {
+			request = request.Param("limit", strconv.FormatInt(options.Limit, 10))
+		}
+		if options.Continue != "" {
+			request = request.Param("continue", options.Continue)
+		}
+		data, err := request.DoRaw(ctx)
+		if err != nil {
+			return err
+		}
+
+		table, listMeta, err := tables.convert(data)
+		if err != nil {
+			return err
+		}
+		if err := emit(watchEvent(table, options, watch.Added)); err != nil {
+			return err
+		}
+		resourceVersion = listMeta.ResourceVersion
+		if listMeta.Continue == "" {
+			break
+		}
+		options.Continue = listMeta.Continue
+	}
+
+	if !options.Watch {
+		return nil
+	}
+	return tables.watch(ctx, resourceVersion, emit)
+}
+
+// serverTables requests a resource as server-side tables and converts them
+// into our tables.
+type serverTables struct {
+	clientset kubernetes.Interface
+	resource  APIResource
+	namespace string
+	options   metav1.ListOptions
+	// Watch events after the first one come without column definitions,
+	// the ones of the list are used for them
+	definitions []metav1.TableColumnDefinition
+}
+
+func (t *serverTables) request() *rest.Request {
+	request := t.clientset.Discovery().RESTClient().Get().
+		AbsPath(resourcePath(t.resource, t.namespace)).
+		SetHeader("Accept", tableAcceptHeader)
+	if t.options.LabelSelector != "" {
+		request = request.Param("labelSelector", t.options.LabelSelector)
+	}
+	if t.options.FieldSelector != "" {
+		request = request.Param("fieldSelector", t.options.FieldSelector)
+	}
+	return request
+}
+
+// watch emits a table for every event after resourceVersion until ctx is
+// cancelled. Watches the server closes are reopened where they stopped.
+func (t *serverTables) watch(ctx context.Context, resourceVersion string, emit func(*Table) error) error {
+	for first := true; ; first = false {
+		if !first {
+			// Same pause as the retry watcher of the typed resources
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(time.Second):
+			}
+		}
+
+		stream, err := t.request().
+			Param("watch", "true").
+			Param("resourceVersion", resourceVersion).
+			Stream(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+
+		decoder := json.NewDecoder(stream)
+		for {
+			var event metav1.WatchEvent
+			if err := decoder.Decode(&event); err != nil {
+				stream.Close()
+				if ctx.Err() != nil {
+					return nil
+				}
+				if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+					break
+				}
+				return err
+			}
+
+			switch watch.EventType(event.Type) {
+			case watch.Added, watch.Modified, watch.Deleted:
+			case watch.Error:
+				stream.Close()
+				var status metav1.Status
+				if err := json.Unmarshal(event.Object.Raw, &status); err != nil {
+					return err
+				}
+				return &apierrors.StatusError{ErrStatus: status}
+			default:
+				continue
+			}
+
+			table, _, err := t.convert(event.Object.Raw)
+			if err != nil {
+				stream.Close()
+				return err
+			}
+			for i := range table.Rows {
+				table.Rows[i].Event = event.Type
+				if accessor, err := meta.Accessor(table.Rows[i].Object); err == nil && accessor.GetResourceVersion() != "" {
+					resourceVersion = accessor.GetResourceVersion()
+				}
+			}
+			if err := emit(table); err != nil {
+				stream.Close()
+				return err
+			}
+		}
+	}
+}
+
+func resourcePath(resource APIResource, namespace string) string {
+	segments := []string{"/apis", resource.GroupVersion.Group, resource.GroupVersion.Version}
+	if resource.GroupVersion.Group == "" {
+		segments = []string{"/api", resource.GroupVersion.Version}
+	}
+	if resource.Namespaced && namespace != "" {
+		segments = append(segments, "namespaces", namespace)
+	}
+	return path.Join(append(segments, resource.Name)...)
+}
+
+// convert turns a server-side Table into our table and returns its list
+// metadata for paging and watching. Servers that can't render tables (some
+// aggregated APIs) send plain lists and objects, which get name and age
+// columns.
+func (t *serverTables) convert(data []byte) (*Table, metav1.ListMeta, error) {
+	var kind metav1.TypeMeta
+	if err := json.Unmarshal(data, &kind); err != nil {
+		return nil, metav1.ListMeta{}, err
+	}
+
+	if kind.Kind != "Table" {
+		list := &unstructured.UnstructuredList{}
+		if strings.HasSuffix(kind.Kind, "List") {
+			if err := list.UnmarshalJSON(data); err != nil {
+				return nil, metav1.ListMeta{}, err
+			}
+		} else {
+			// A watch event carries a single object
+			item := unstructured.Unstructured{}
+			if err := item.UnmarshalJSON(data); err != nil {
+				return nil, metav1.ListMeta{}, err
+			}
+			list.Items = append(list.Items, item)
+		}
+		table := NewTable(t.resource.FullName(), Column{Header: "NAME"}, Column{Header: "AGE"})
+		for i := range list.Items {
+			item := &list.Items[i]
+			table.Append(item.GetName(), item, item.GetName(), HumanReadableDuration(time.Since(item.GetCreationTimestamp().Time)))
+		}
+		return table, metav1.ListMeta{ResourceVersion: list.GetResourceVersion(), Continue: list.GetContinue()}, nil
+	}
+
+	var serverTable metav1.Table
+	if err := json.Unmarshal(data, &serverTable); err != nil {
+		return nil, metav1.ListMeta{}, err
+	}
+	if len(serverTable.ColumnDefinitions) > 0 {
+		t.definitions = serverTable.ColumnDefinitions
+	}
+
+	table := NewTable(t.resource.FullName())
+	for _, definition := range t.definitions {
+		// Priority 0 columns are the default view, the rest are for -o wide
+		table.Columns = append(table.Columns, Column{Header: strings.ToUpper(definition.Name), Wide: definition.Priority > 0})
+	}
+	for _, row := range serverTable.Rows {
+		cells := make([]string, len(row.Cells))
+		for i, cell := range row.Cells {
+			if i < len(t.definitions) {
+				cells[i] = formatTableCell(cell, t.definitions[i])
+			}
+		}
+
+		var object metav1.PartialObjectMetadata
+		name := ""
+		if len(row.Object.Raw) > 0 && json.Unmarshal(row.Object.Raw, &object) == nil {
+			name = object.Name
+		} else if len(cells) > 0 {
+			name = cells[0]
+		}
+		table.Append(name, &object, cells...)
+	}
+	return table, serverTable.ListMeta, nil
+}
+
+func formatTableCell(value interface{}, column metav1.TableColumnDefinition) string {
+	switch v := value.(type) {
+	case nil:
+		return "<none>"
+	case string:
+		if column.Type == "date" {
+			if t, err := time.Parse(time.RFC3339, v); err == nil {
+				return HumanReadableDuration(time.Since(t))
+			}
+		}
+		return v
+	case float64:
+		if v == math.Trunc(v) {
+			return strconv.FormatInt(int64(v), 10)
+		}
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(data)
+}
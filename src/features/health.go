@@ -0,0 +1,170 @@
+package features
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+const DefaultCheckTimeout = 5 * time.Second
+
+const (
+	AuthOK           = "ok"
+	AuthUnauthorized = "unauthorized"
+	AuthForbidden    = "forbidden"
+	AuthExpired      = "expired"
+	AuthUnknown      = "-"
+)
+
+type ContextHealth struct {
+	Context   string        `json:"context"`
+	Server    string        `json:"server"`
+	Reachable bool          `json:"reachable"`
+	Auth      string        `json:"auth"`
+	Version   string        `json:"version,omitempty"`
+	Latency   time.Duration `json:"latency,omitempty"`
+	Error     string        `json:"error,omitempty"`
+}
+
+// CheckContexts checks every context of config in parallel, each bounded by
+// timeout, and returns the results in context name order.
+func CheckContexts(config *clientcmdapi.Config, timeout time.Duration) []ContextHealth {
+	names := SortedContextNames(config)
+	results := make([]ContextHealth, len(names))
+
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			results[i] = CheckContext(config, name, timeout)
+		}(i, name)
+	}
+	wg.Wait()
+
+	return results
+}
+
+func CheckContext(config *clientcmdapi.Config, contextName string, timeout time.Duration) ContextHealth {
+	health := ContextHealth{Context: contextName, Auth: AuthUnknown}
+	if context, ok := config.Contexts[contextName]; ok {
+		if cluster, ok := config.Clusters[context.Cluster]; ok {
+			health.Server = cluster.Server
+		}
+	}
+
+	restConfig, err := clientcmd.NewNonInteractiveClientConfig(*config, contextName, &clientcmd.ConfigOverrides{}, nil).ClientConfig()
+	if err != nil {
+		health.Error = err.Error()
+		return health
+	}
+
+	// Exec credential plugins are not bound by the HTTP timeout, so the whole
+	// check runs against its own deadline.
+	done := make(chan ContextHealth, 1)
+	go func() {
+		done <- CheckServer(restConfig, timeout)
+	}()
+	select {
+	case result := <-done:
+		result.Context = health.Context
+		result.Server = health.Server
+		return result
+	case <-time.After(timeout):
+		health.Error = fmt.Sprintf("timed out after %s", timeout)
+		return health
+	}
+}
+
+// CheckServer calls the /version endpoint of the API server described by
+// restConfig and classifies the outcome.
+func CheckServer(restConfig *rest.Config, timeout time.Duration) ContextHealth {
+	health := ContextHealth{Server: restConfig.Host, Auth: AuthUnknown}
+
+	checkConfig := rest.CopyConfig(restConfig)
+	checkConfig.Timeout = timeout
+	client, err := discovery.NewDiscoveryClientForConfig(checkConfig)
+	if err != nil {
+		health.Error = err.Error()
+		return health
+	}
+
+	start := time.Now()
+	info, err := client.ServerVersion()
+	health.Latency = time.Since(start)
+
+	switch {
+	case err == nil:
+		health.Reachable = true
+		health.Auth = AuthOK
+		health.Version = info.GitVersion
+	case apierrors.IsUnauthorized(err):
+		health.Reachable = true
+		health.Auth = AuthUnauthorized
+		health.Error = err.Error()
+	case apierrors.IsForbidden(err):
+		health.Reachable = true
+		health.Auth = AuthForbidden
+		health.Error = err.Error()
+	case strings.Contains(err.Error(), "tls: expired certificate"):
+		// The server rejected our client certificate during the handshake
+		health.Reachable = true
+		health.Auth = AuthExpired
+		health.Error = err.Error()
+	default:
+		health.Error = err.Error()
+	}
+	return health
+}
+
+func ShowContextHealth(results []ContextHealth, currentContext string) {
+	PrintHeading("Available Kubernetes contexts:\n")
+
+	table := NewTable("context",
+		Column{Header: "Context Name"},
+		Column{Header: "Server"},
+		Column{Header: "Reachable"},
+		Column{Header: "Auth"},
+		Column{Header: "Version"},
+		Column{Header: "Latency"},
+		Column{Header: "Error"},
+	)
+	for _, result := range results {
+		name := result.Context
+		if name == currentContext {
+			name = "* " + name
+		}
+		reachable := "no"
+		if result.Reachable {
+			reachable = "yes"
+		}
+		latency := "-"
+		if result.Latency > 0 {
+			latency = result.Latency.Round(time.Millisecond).String()
+		}
+		version := result.Version
+		if version == "" {
+			version = "-"
+		}
+		errorMessage := truncate(result.Error, 60)
+		if outputFormat == OutputWide {
+			errorMessage = result.Error
+		}
+		table.Append(result.Context, result, name, result.Server, reachable, result.Auth, version, latency, errorMessage)
+	}
+	RenderTable(table)
+}
+
+func truncate(value string, length int) string {
+	if len(value) <= length {
+		return value
+	}
+	return value[:length-3] + "..."
+}
@@ -0,0 +1,186 @@
+package features
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/rest"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+const testTimeout = 200 * time.Millisecond
+
+func versionHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{"major":"1","minor":"30","gitVersion":"v1.30.2"}`))
+}
+
+func statusHandler(code int, reason string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(code)
+		fmt.Fprintf(w, `{"kind":"Status","apiVersion":"v1","status":"Failure","reason":%q,"code":%d}`, reason, code)
+	}
+}
+
+func pemEncode(kind string, der []byte) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: kind, Bytes: der})
+}
+
+// newCertificate creates a certificate signed by parent, or self-signed when
+// parent is nil, and returns it with its key.
+func newCertificate(t *testing.T, template *x509.Certificate, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return cert, key
+}
+
+func TestCheckServerReachable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(versionHandler))
+	defer server.Close()
+
+	health := CheckServer(&rest.Config{Host: server.URL}, testTimeout)
+	assert.True(t, health.Reachable)
+	assert.Equal(t, AuthOK, health.Auth)
+	assert.Equal(t, "v1.30.2", health.Version)
+	assert.Empty(t, health.Error)
+}
+
+func TestCheckServerUnauthorized(t *testing.T) {
+	server := httptest.NewServer(statusHandler(http.StatusUnauthorized, "Unauthorized"))
+	defer server.Close()
+
+	health := CheckServer(&rest.Config{Host: server.URL}, testTimeout)
+	assert.True(t, health.Reachable)
+	assert.Equal(t, AuthUnauthorized, health.Auth)
+}
+
+func TestCheckServerForbidden(t *testing.T) {
+	server := httptest.NewServer(statusHandler(http.StatusForbidden, "Forbidden"))
+	defer server.Close()
+
+	health := CheckServer(&rest.Config{Host: server.URL}, testTimeout)
+	assert.True(t, health.Reachable)
+	assert.Equal(t, AuthForbidden, health.Auth)
+}
+
+func TestCheckServerExpiredClientCertificate(t *testing.T) {
+	ca, caKey := newCertificate(t, &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}, nil, nil)
+	client, clientKey := newCertificate(t, &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "expired-user"},
+		NotBefore:    time.Now().Add(-48 * time.Hour),
+		NotAfter:     time.Now().Add(-24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, ca, caKey)
+	clientKeyDER, err := x509.MarshalECPrivateKey(clientKey)
+	require.NoError(t, err)
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(ca)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(versionHandler))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	server.StartTLS()
+	defer server.Close()
+
+	health := CheckServer(&rest.Config{
+		Host: server.URL,
+		TLSClientConfig: rest.TLSClientConfig{
+			CAData:   pemEncode("CERTIFICATE", server.Certificate().Raw),
+			CertData: pemEncode("CERTIFICATE", client.Raw),
+			KeyData:  pemEncode("EC PRIVATE KEY", clientKeyDER),
+		},
+	}, testTimeout)
+	assert.True(t, health.Reachable)
+	assert.Equal(t, AuthExpired, health.Auth)
+}
+
+func TestCheckContextTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	config := clientcmdapi.NewConfig()
+	config.Clusters["slow"] = &clientcmdapi.Cluster{Server: server.URL}
+	config.AuthInfos["user"] = &clientcmdapi.AuthInfo{}
+	config.Contexts["slow"] = &clientcmdapi.Context{Cluster: "slow", AuthInfo: "user"}
+
+	start := time.Now()
+	health := CheckContext(config, "slow", testTimeout)
+	assert.Less(t, time.Since(start), 5*testTimeout)
+	assert.False(t, health.Reachable)
+	assert.Equal(t, AuthUnknown, health.Auth)
+	assert.NotEmpty(t, health.Error)
+	assert.Equal(t, server.URL, health.Server)
+}
+
+func TestCheckContextRelativeCertificateAuthority(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(versionHandler))
+	defer server.Close()
+
+	// The CA file sits next to the kubeconfig, not in the working directory
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "ca.crt"), pemEncode("CERTIFICATE", server.Certificate().Raw), 0600))
+	kubeconfigPath := filepath.Join(dir, "config")
+	require.NoError(t, os.WriteFile(kubeconfigPath, []byte(`apiVersion: v1
+kind: Config
+clusters:
+- name: test
+  cluster:
+    server: `+server.URL+`
+    certificate-authority: ca.crt
+contexts:
+- name: test
+  context:
+    cluster: test
+    user: test
+current-context: test
+users:
+- name: test
+  user: {}
+`), 0600))
+
+	config, err := LoadKubeconfig(kubeconfigPath)
+	require.NoError(t, err)
+	health := CheckContext(config, "test", time.Second)
+	assert.True(t, health.Reachable, health.Error)
+	assert.Equal(t, AuthOK, health.Auth)
+}
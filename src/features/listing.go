@@ -0,0 +1,127 @@
+package features
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// DefaultChunkSize is the page size of List calls, the same as kubectl's.
+const DefaultChunkSize = 500
+
+// MaxListWorkers bounds the List calls in flight when namespaces have to be
+// listed one by one.
+const MaxListWorkers = 8
+
+// ListFunc lists a resource in one namespace, or in all of them for
+// metav1.NamespaceAll, and hands emit a table per page.
+type ListFunc func(ctx context.Context, namespace string, emit func(*Table) error) error
+
+// ListAllNamespaces lists with a single cluster-wide call. When RBAC only
+// allows listing inside namespaces it falls back to listing every namespace
+// the user can see, skipping the ones it isn't allowed into.
+func ListAllNamespaces(ctx context.Context, clientset kubernetes.Interface, list ListFunc, printer *TablePrinter) error {
+	printed := false
+	err := list(ctx, metav1.NamespaceAll, func(table *Table) error {
+		printed = true
+		table.AllNamespaces = true
+		return printer.Print(table)
+	})
+	if err == nil || printed || !apierrors.IsForbidden(err) {
+		return err
+	}
+	if printer.events {
+		// The per-namespace lists would each block on their watch
+		return fmt.Errorf("%v, watching needs permission to list across all namespaces, pick one with -n", err)
+	}
+
+	nsList, nsErr := clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	if nsErr != nil {
+		// Not allowed to list namespaces either, the first error says why
+		return err
+	}
+	names := make([]string, 0, len(nsList.Items))
+	for _, ns := range nsList.Items {
+		names = append(names, ns.Name)
+	}
+	return ListNamespaces(ctx, names, list, printer, true)
+}
+
+// ListNamespaces lists each of names with at most MaxListWorkers calls at a
+// time and prints them into one table in the order given, each namespace as
+// soon as the ones before it are done. The pages of a namespace are kept
+// until then.
+func ListNamespaces(ctx context.Context, names []string, list ListFunc, printer *TablePrinter, skipForbidden bool) error {
+	for _, name := range names {
+		printer.Reserve("NAMESPACE", len(name))
+	}
+
+	type result struct {
+		tables []*Table
+		err    error
+	}
+	results := make([]chan result, len(names))
+	for i := range results {
+		results[i] = make(chan result, 1)
+	}
+
+	// Stops the workers when we return early on an error
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	next := make(chan int)
+	go func() {
+		defer close(next)
+		for i := range names {
+			select {
+			case next <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	for w := 0; w < min(MaxListWorkers, len(names)); w++ {
+		go func() {
+			for i := range next {
+				var tables []*Table
+				err := list(ctx, names[i], func(table *Table) error {
+					tables = append(tables, table)
+					return nil
+				})
+				results[i] <- result{tables: tables, err: err}
+			}
+		}()
+	}
+
+	var forbidden []string
+	var forbiddenErr error
+	for i, name := range names {
+		result := <-results[i]
+		if skipForbidden && apierrors.IsForbidden(result.err) {
+			forbidden = append(forbidden, name)
+			forbiddenErr = result.err
+			continue
+		}
+		if result.err != nil {
+			return fmt.Errorf("namespace %s: %v", name, result.err)
+		}
+		for _, table := range result.tables {
+			table.AllNamespaces = true
+			if err := printer.Print(table); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(forbidden) > 0 && len(forbidden) == len(names) {
+		return forbiddenErr
+	}
+	if len(forbidden) > 0 {
+		printer.Notef("> Skipped %d namespace(s) without list permission: %s", len(forbidden), strings.Join(forbidden, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package features
+
+import (
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// ActiveKubeconfig returns the file chosen on the command line, in order of
+// precedence -f, a file picked by "load" and --kubeconfig. An empty result
+// means the standard rules apply: $KUBECONFIG (merged) or ~/.kube/config.
+func ActiveKubeconfig() string {
+	switch {
+	case loadFile != "":
+		return loadFile
+	case selectedConfig != "":
+		return selectedConfig
+	default:
+		return kubeconfig
+	}
+}
+
+func LoadingRules(kubeconfigPath string) *clientcmd.ClientConfigLoadingRules {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	rules.ExplicitPath = kubeconfigPath
+	return rules
+}
+
+// PathOptions is what clientcmd.ModifyConfig uses to decide which file each
+// change is written to: contexts, clusters and users go back to the file they
+// were loaded from, current-context to the default file of the chain.
+func PathOptions(kubeconfigPath string) *clientcmd.PathOptions {
+	pathOptions := clientcmd.NewDefaultPathOptions()
+	pathOptions.LoadingRules.ExplicitPath = kubeconfigPath
+	return pathOptions
+}
+
+// LoadKubeconfig merges the loading chain for reading. Relative certificate,
+// key and token file paths are resolved against the file each entry comes
+// from, so write changes with EditKubeconfig or ModifyKubeconfig instead of
+// writing this config back.
+func LoadKubeconfig(kubeconfigPath string) (*clientcmdapi.Config, error) {
+	config, err := PathOptions(kubeconfigPath).GetStartingConfig()
+	if err != nil {
+		return nil, err
+	}
+	// PathOptions loads with DoNotResolvePaths, which is right for writing
+	// back but leaves relative paths to be read from the working directory
+	if err := clientcmd.ResolveLocalPaths(config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+func GetRestConfig(kubeconfigPath string) (*rest.Config, error) {
+	clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(LoadingRules(kubeconfigPath), &clientcmd.ConfigOverrides{})
+	return clientConfig.ClientConfig()
+}
+
+// ContextOrigin returns the file that defines the named context, falling back
+// to the file new entries would be written to.
+func ContextOrigin(kubeconfigPath string, config *clientcmdapi.Config, contextName string) string {
+	if context, ok := config.Contexts[contextName]; ok && context.LocationOfOrigin != "" {
+		return context.LocationOfOrigin
+	}
+	return PathOptions(kubeconfigPath).GetDefaultFilename()
+}
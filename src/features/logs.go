@@ -0,0 +1,165 @@
+package features
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// LogSource is one container whose logs are read.
+type LogSource struct {
+	Namespace string
+	Pod       string
+	Container string
+}
+
+// LogSources picks the containers of a pod to read logs from: the one named
+// in options, every container with allContainers, otherwise the default
+// container like kubectl logs does.
+func LogSources(ctx context.Context, clientset kubernetes.Interface, namespace string, podName string, options *corev1.PodLogOptions, allContainers bool) ([]LogSource, error) {
+	if options.Container != "" {
+		return []LogSource{{Namespace: namespace, Pod: podName, Container: options.Container}}, nil
+	}
+
+	pod, err := clientset.CoreV1().Pods(namespace).Get(ctx, podName, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, container := range pod.Spec.InitContainers {
+		names = append(names, container.Name)
+	}
+	for _, container := range pod.Spec.Containers {
+		names = append(names, container.Name)
+	}
+	for _, container := range pod.Spec.EphemeralContainers {
+		names = append(names, container.Name)
+	}
+
+	if !allContainers {
+		container := defaultContainer(pod)
+		if len(pod.Spec.Containers) > 1 {
+			fmt.Fprintf(os.Stderr, "> Defaulted container %s out of: %s\n", container, strings.Join(names, ", "))
+		}
+		names = []string{container}
+	}
+
+	sources := make([]LogSource, 0, len(names))
+	for _, name := range names {
+		sources = append(sources, LogSource{Namespace: namespace, Pod: pod.Name, Container: name})
+	}
+	return sources, nil
+}
+
+// StreamLogs copies the logs of sources to out as they arrive. The lines of
+// more than one source are prefixed with where they came from, and with
+// options.Follow all of them are followed at once.
+func StreamLogs(ctx context.Context, clientset kubernetes.Interface, sources []LogSource, options corev1.PodLogOptions, out io.Writer) error {
+	if len(sources) == 1 {
+		return streamLog(ctx, clientset, sources[0], options, func(stream io.Reader) error {
+			_, err := io.Copy(out, stream)
+			return err
+		})
+	}
+
+	var mu sync.Mutex
+	prefixed := func(source LogSource) error {
+		prefix := fmt.Sprintf("[pod/%s/%s] ", source.Pod, source.Container)
+		return streamLog(ctx, clientset, source, options, func(stream io.Reader) error {
+			return copyLines(out, &mu, prefix, stream)
+		})
+	}
+
+	if !options.Follow {
+		for _, source := range sources {
+			if err := prefixed(source); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	// Followed streams never end, the first error stops them all
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	errs := make(chan error, len(sources))
+	var wg sync.WaitGroup
+	for _, source := range sources {
+		wg.Add(1)
+		go func(source LogSource) {
+			defer wg.Done()
+			if err := prefixed(source); err != nil {
+				errs <- err
+				cancel()
+			}
+		}(source)
+	}
+	wg.Wait()
+	close(errs)
+	return <-errs
+}
+
+// streamLog opens the log stream of source and hands it to read. Streams
+// cut short by ctx are not an error.
+func streamLog(ctx context.Context, clientset kubernetes.Interface, source LogSource, options corev1.PodLogOptions, read func(io.Reader) error) error {
+	options.Container = source.Container
+	stream, err := clientset.CoreV1().Pods(source.Namespace).GetLogs(source.Pod, &options).Stream(ctx)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return fmt.Errorf("pod %s container %s: %v", source.Pod, source.Container, err)
+	}
+	defer stream.Close()
+
+	if err := read(stream); err != nil && ctx.Err() == nil {
+		return err
+	}
+	return nil
+}
+
+// copyLines writes every line of r to out behind prefix. mu keeps the lines
+// of streams read in parallel whole.
+func copyLines(out io.Writer, mu *sync.Mutex, prefix string, r io.Reader) error {
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			if line[len(line)-1] != '\n' {
+				line = append(line, '\n')
+			}
+			mu.Lock()
+			_, writeErr := fmt.Fprintf(out, "%s%s", prefix, line)
+			mu.Unlock()
+			if writeErr != nil {
+				return writeErr
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// defaultContainer is the container kubectl logs picks without -c.
+func defaultContainer(pod *corev1.Pod) string {
+	if name := pod.Annotations["kubectl.kubernetes.io/default-container"]; name != "" {
+		return name
+	}
+	if len(pod.Spec.Containers) > 0 {
+		return pod.Spec.Containers[0].Name
+	}
+	return ""
+}
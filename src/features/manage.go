@@ -0,0 +1,188 @@
+package features
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	survey "github.com/AlecAivazis/survey/v2"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// EditKubeconfig applies edit to every existing file in the loading chain and
+// writes back only the files it changed. Entries can live in any file of the
+// chain, so editing them file by file keeps each one where it was defined.
+func EditKubeconfig(kubeconfigPath string, edit func(config *clientcmdapi.Config)) ([]string, error) {
+	var changed []string
+	for _, file := range LoadingRules(kubeconfigPath).GetLoadingPrecedence() {
+		if _, err := os.Stat(file); err != nil {
+			continue
+		}
+		config, err := clientcmd.LoadFromFile(file)
+		if err != nil {
+			return changed, err
+		}
+
+		before := config.DeepCopy()
+		edit(config)
+		if reflect.DeepEqual(before, config) {
+			continue
+		}
+
+		if err := WriteKubeconfig(file, config); err != nil {
+			return changed, err
+		}
+		changed = append(changed, file)
+	}
+	return changed, nil
+}
+
+func RenameContext(kubeconfigPath string, oldName string, newName string) error {
+	config, err := LoadKubeconfig(kubeconfigPath)
+	if err != nil {
+		return err
+	}
+	if _, ok := config.Contexts[oldName]; !ok {
+		return fmt.Errorf("context not found: %s", oldName)
+	}
+	if _, ok := config.Contexts[newName]; ok {
+		return fmt.Errorf("context already exists: %s", newName)
+	}
+
+	changed, err := EditKubeconfig(kubeconfigPath, func(config *clientcmdapi.Config) {
+		if context, ok := config.Contexts[oldName]; ok {
+			config.Contexts[newName] = context
+			delete(config.Contexts, oldName)
+		}
+		if config.CurrentContext == oldName {
+			config.CurrentContext = newName
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	if !dryRun {
+		fmt.Printf("\n> Successfully rename context %s to: %s (%s)\n", oldName, newName, strings.Join(changed, ", "))
+	}
+	return nil
+}
+
+// OrphanedEntries lists the clusters and users of config that no context
+// refers to anymore.
+func OrphanedEntries(config *clientcmdapi.Config) ([]string, []string) {
+	usedClusters := make(map[string]bool)
+	usedAuthInfos := make(map[string]bool)
+	for _, context := range config.Contexts {
+		usedClusters[context.Cluster] = true
+		usedAuthInfos[context.AuthInfo] = true
+	}
+
+	var clusters, authInfos []string
+	for _, name := range sortedKeys(config.Clusters) {
+		if !usedClusters[name] {
+			clusters = append(clusters, name)
+		}
+	}
+	for _, name := range sortedKeys(config.AuthInfos) {
+		if !usedAuthInfos[name] {
+			authInfos = append(authInfos, name)
+		}
+	}
+	return clusters, authInfos
+}
+
+func DeleteContext(kubeconfigPath string, contextName string, prune bool) error {
+	config, err := LoadKubeconfig(kubeconfigPath)
+	if err != nil {
+		return err
+	}
+	if _, ok := config.Contexts[contextName]; !ok {
+		return fmt.Errorf("context not found: %s", contextName)
+	}
+
+	// Only entries orphaned by this delete are offered for cleanup
+	clustersBefore, authInfosBefore := OrphanedEntries(config)
+	delete(config.Contexts, contextName)
+	clustersAfter, authInfosAfter := OrphanedEntries(config)
+	clusters := subtract(clustersAfter, clustersBefore)
+	authInfos := subtract(authInfosAfter, authInfosBefore)
+
+	if len(clusters) > 0 || len(authInfos) > 0 {
+		fmt.Printf("> Entries no longer referenced by any context:\n")
+		for _, name := range clusters {
+			fmt.Printf("  cluster: %s\n", name)
+		}
+		for _, name := range authInfos {
+			fmt.Printf("  user:    %s\n", name)
+		}
+
+		if !prune && IsInteractive() {
+			prompt := &survey.Confirm{
+				Message: "Remove these entries too?",
+				Default: true,
+			}
+			if err := survey.AskOne(prompt, &prune); err != nil {
+				return err
+			}
+		}
+		if !prune {
+			fmt.Println("> Keeping them, use --prune to remove")
+			clusters, authInfos = nil, nil
+		}
+	}
+
+	changed, err := EditKubeconfig(kubeconfigPath, func(config *clientcmdapi.Config) {
+		delete(config.Contexts, contextName)
+		if config.CurrentContext == contextName {
+			config.CurrentContext = ""
+		}
+		for _, name := range clusters {
+			delete(config.Clusters, name)
+		}
+		for _, name := range authInfos {
+			delete(config.AuthInfos, name)
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	if !dryRun {
+		fmt.Printf("\n> Successfully delete context: %s (%s)\n", contextName, strings.Join(changed, ", "))
+	}
+	return nil
+}
+
+func ExportContext(kubeconfigPath string, contextName string, flatten bool) ([]byte, error) {
+	config, err := LoadKubeconfig(kubeconfigPath)
+	if err != nil {
+		return nil, err
+	}
+	exported, err := ExtractContext(config, contextName)
+	if err != nil {
+		return nil, err
+	}
+	if flatten {
+		if err := clientcmdapi.FlattenConfig(exported); err != nil {
+			return nil, err
+		}
+	}
+	return clientcmd.Write(*exported)
+}
+
+func subtract(values []string, remove []string) []string {
+	removed := make(map[string]bool)
+	for _, value := range remove {
+		removed[value] = true
+	}
+	var result []string
+	for _, value := range values {
+		if !removed[value] {
+			result = append(result, value)
+		}
+	}
+	return result
+}
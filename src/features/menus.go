@@ -4,9 +4,10 @@ import (
 	"bytes"
 	"context"
 	"fmt"
-	"io"
+	"math"
 	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"strings"
 	"time"
@@ -19,7 +20,6 @@ import (
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/tools/portforward"
 	"k8s.io/client-go/transport/spdy"
-	"k8s.io/client-go/util/homedir"
 )
 
 const (
@@ -38,10 +38,15 @@ Apache v2.0 License`
 )
 
 func GetCommands() []*cobra.Command {
-	if home := homedir.HomeDir(); home != "" {
-		kubeconfig = filepath.Join(home, ".kube", "config")
+	rootCmd, commands := NewCommands()
+	if err := rootCmd.Execute(); err != nil {
+		logrus.Fatalf("error executing command: %v", err)
 	}
+	return commands
+}
 
+// NewCommands builds the root command and the commands under it.
+func NewCommands() (*cobra.Command, []*cobra.Command) {
 	kc := &KubeConfig{}
 
 	versionCmd := &cobra.Command{
@@ -62,11 +67,25 @@ func GetCommands() []*cobra.Command {
 				// Print the list of context names
 				fmt.Println("No available contexts!")
 			} else {
-				// Get the map of context name to context config
-				config, err := clientcmd.Load(configBytes)
+				// Loaded with relative certificate paths resolved, the checks
+				// read those files
+				config, err := LoadKubeconfig(ActiveKubeconfig())
+				if err != nil {
+					return err
+				}
+
+				check, err := cmd.Flags().GetBool("check")
 				if err != nil {
 					return err
 				}
+				if check {
+					timeout, err := cmd.Flags().GetDuration("timeout")
+					if err != nil {
+						return err
+					}
+					ShowContextHealth(CheckContexts(config, timeout), config.CurrentContext)
+					return nil
+				}
 				ShowDetailList(config)
 			}
 			return nil
@@ -118,7 +137,7 @@ func GetCommands() []*cobra.Command {
 			if err := kc.Load(); err != nil {
 				return err
 			}
-			selectedConfig := strings.Join(kc.Files, "\n")
+			selectedConfig = strings.Join(kc.Files, "\n")
 			fmt.Printf("Loaded kubeconfig file(s):\n%s\n", selectedConfig)
 
 			// Get the map of context name to context config
@@ -141,8 +160,18 @@ func GetCommands() []*cobra.Command {
 		Use:   "merge",
 		Short: "Merge multiple kubeconfig files",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			strategy, err := ParseMergeStrategy(mergeStrategy)
+			if err != nil {
+				return err
+			}
 			kc.Files = args
-			if err := kc.Load(); err != nil {
+			kc.Strategy = strategy
+			err = kc.Load()
+			// Always report conflicts before anything is written to disk
+			if reportErr := ShowConflictReport(kc.Conflicts); reportErr != nil {
+				return reportErr
+			}
+			if err != nil {
 				return err
 			}
 			mergedFile := "merged-config"
@@ -152,22 +181,63 @@ func GetCommands() []*cobra.Command {
 			if err := kc.SaveToFile(mergedFile); err != nil {
 				return err
 			}
-			fmt.Printf("Merged kubeconfig files:\n%s\n", kc.Files)
-			fmt.Printf("Saved merged kubeconfig to file: %s\n", mergedFile)
+			if dryRun {
+				return nil
+			}
+			PrintHeading("Merged kubeconfig files:\n%s\n", kc.Files)
+			PrintHeading("Saved merged kubeconfig to file: %s\n", mergedFile)
+			return nil
+		},
+	}
+
+	splitCmd := &cobra.Command{
+		Use:   "split [file]",
+		Short: "Split a kubeconfig file into one file per context",
+		Long:  "Split a kubeconfig file into one self-contained file per context, holding only the cluster and user that context references with their certificate and key files embedded. Nothing is written when any context is broken",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			outDir, err := cmd.Flags().GetString("out-dir")
+			if err != nil {
+				return err
+			}
+
+			kc.Files = args
+			if err := kc.Load(); err != nil {
+				return err
+			}
+			files, err := kc.Split(outDir)
+			if err != nil {
+				return err
+			}
+			if dryRun {
+				return nil
+			}
+
+			table := NewTable("context",
+				Column{Header: "Context Name"},
+				Column{Header: "File"},
+			)
+			for _, contextName := range sortedKeys(files) {
+				table.Append(contextName, SplitFile{Context: contextName, File: files[contextName]}, contextName, files[contextName])
+			}
+			if err := PrintTable(table); err != nil {
+				return err
+			}
+			PrintHeading("Split %s into %d file(s) in: %s\n", args[0], len(files), outDir)
 			return nil
 		},
 	}
 
 	getCmd := &cobra.Command{
 		Use:   "get",
-		Short: "Get Kubernetes resources (ns, svc, deploy, po, ep)",
-		Long:  "Get Kubernetes resources: namespace (ns), services (svc), deployments (deploy), pods (po), endpoints (ep)",
-		RunE: func(cmd *cobra.Command, args []string) error {
+		Short: "Get Kubernetes resources (ns, svc, deploy, sts, ds, rs, job, cj, po, ep, ing, netpol, httproute, cm, secrets, pvc, pv, sc)",
+		Long:  "Get Kubernetes resources: namespace (ns), services (svc), deployments (deploy), statefulsets (sts), daemonsets (ds), replicasets (rs), jobs (job), cronjobs (cj), pods (po), endpoints (ep), ingresses (ing), networkpolicies (netpol), httproutes (Gateway API, when installed), configmaps (cm), secrets, persistentvolumeclaims (pvc), persistentvolumes (pv), storageclasses (sc). Any other resource, including CRDs, is found through discovery by name, short name, <name>.<group> or <group>/<version>/<kind>",
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
 			if len(args) < 1 {
 				return fmt.Errorf("resource type not specified")
 			}
 
-			clientset, err := GetClientSet(kubeconfig)
+			clientset, err := GetClientSet(ActiveKubeconfig())
 			if err != nil {
 				return err
 			}
@@ -180,123 +250,117 @@ func GetCommands() []*cobra.Command {
 				return err
 			}
 
-			if len(namespaces) == 0 {
-				// If namespace is not specified, get all namespaces
-				nsList, err := clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+			selector, err := cmd.Flags().GetString("selector")
+			if err != nil {
+				return err
+			}
+			fieldSelector, err := cmd.Flags().GetString("field-selector")
+			if err != nil {
+				return err
+			}
+			chunkSize, err := cmd.Flags().GetInt64("chunk-size")
+			if err != nil {
+				return err
+			}
+			if chunkSize < 0 {
+				return fmt.Errorf("--chunk-size must not be negative")
+			}
+			watchChanges, err := cmd.Flags().GetBool("watch")
+			if err != nil {
+				return err
+			}
+			listOptions := metav1.ListOptions{LabelSelector: selector, FieldSelector: fieldSelector, Limit: chunkSize, Watch: watchChanges}
+
+			var podFilter PodFilter
+			if podFilter.Status, err = cmd.Flags().GetString("status"); err != nil {
+				return err
+			}
+			if podFilter.Node, err = cmd.Flags().GetString("node"); err != nil {
+				return err
+			}
+			if podFilter.Image, err = cmd.Flags().GetString("image"); err != nil {
+				return err
+			}
+			if !podFilter.IsEmpty() && resource != "pods" && resource != "po" {
+				return fmt.Errorf("--status, --node and --image only apply to pods")
+			}
+
+			// Everything else, CRDs included, is resolved through discovery
+			var apiResource *APIResource
+			if resource != "namespaces" && resource != "ns" && !IsTypedResource(resource) {
+				resolved, err := ResolveResource(clientset.Discovery(), resource)
 				if err != nil {
 					return err
 				}
-				for _, ns := range nsList.Items {
-					namespace := ns.Name
-					fmt.Printf("Namespace: %s\n", namespace)
-					switch resource {
-
-					case "pods", "po":
-						pods, err := clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
-						if err != nil {
-							return err
-						}
-						ShowPodsByFilter(pods)
-
-					case "namespaces", "ns":
-						var namespaces *corev1.NamespaceList
-						if namespace != "" {
-							ns, err := clientset.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{})
-							if err != nil {
-								return err
-							}
-							namespaces = &corev1.NamespaceList{Items: []corev1.Namespace{*ns}}
-						} else {
-							ns, err := clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
-							if err != nil {
-								return err
-							}
-							namespaces = ns
-						}
-						ShowNamespaceByFilter(namespaces)
-
-					case "services", "svc":
-						services, err := clientset.CoreV1().Services(namespace).List(ctx, metav1.ListOptions{})
-						if err != nil {
-							return err
-						}
-						ShowServiceByFilter(services)
-
-					case "endpoints", "ep":
-						endpoints, err := clientset.CoreV1().Endpoints(namespace).List(ctx, metav1.ListOptions{})
-						if err != nil {
-							return err
-						}
-						ShowEndpointByFilter(endpoints)
-
-					case "deployment", "deploy":
-						deployments, err := clientset.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{})
-						if err != nil {
-							return err
-						}
-						ShowDeploymentByFilter(deployments)
-
-					default:
-						return fmt.Errorf("unknown resource type: %s", resource)
-					}
+				// Secrets however they are spelled, e.g. Secret or v1/Secret, get
+				// the typed table that keeps their values redacted
+				if resolved.GroupVersionResource() == corev1.SchemeGroupVersion.WithResource("secrets") {
+					resource = "secrets"
+				} else {
+					apiResource = &resolved
 				}
-			} else {
-				// If namespace is specified, get resources only in those namespaces
-				for _, namespace := range namespaces {
-					fmt.Printf("Namespace: %s\n", namespace)
-					switch resource {
-					case "pods", "po":
-						pods, err := clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
-						if err != nil {
-							return err
-						}
-						ShowPodsByFilter(pods)
-
-					case "namespaces", "ns":
-						var namespaces *corev1.NamespaceList
-						if namespace != "" {
-							ns, err := clientset.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{})
-							if err != nil {
-								return err
-							}
-							namespaces = &corev1.NamespaceList{Items: []corev1.Namespace{*ns}}
-						} else {
-							ns, err := clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
-							if err != nil {
-								return err
-							}
-							namespaces = ns
-						}
-						ShowNamespaceByFilter(namespaces)
+			}
 
-					case "services", "svc":
-						services, err := clientset.CoreV1().Services(namespace).List(ctx, metav1.ListOptions{})
-						if err != nil {
-							return err
-						}
-						ShowServiceByFilter(services)
+			if revealSecrets {
+				if resource != "secrets" && resource != "secret" {
+					return fmt.Errorf("--reveal only applies to secrets")
+				}
+				if err := ConfirmReveal(); err != nil {
+					return err
+				}
+			}
 
-					case "endpoints", "ep":
-						endpoints, err := clientset.CoreV1().Endpoints(namespace).List(ctx, metav1.ListOptions{})
-						if err != nil {
-							return err
-						}
-						ShowEndpointByFilter(endpoints)
+			printer := NewTablePrinter()
+			if watchChanges {
+				printer.ShowEvents()
+				// Ctrl-C ends the watch, the table still gets closed
+				var stop context.CancelFunc
+				ctx, stop = signal.NotifyContext(ctx, os.Interrupt)
+				defer stop()
+			}
+			// Close the table even after an error so what was printed is complete
+			defer func() {
+				if closeErr := printer.Close(); err == nil {
+					err = closeErr
+				}
+			}()
 
-					case "deployment", "deploy":
-						deployments, err := clientset.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{})
-						if err != nil {
-							return err
-						}
-						ShowDeploymentByFilter(deployments)
+			if resource == "namespaces" || resource == "ns" {
+				return ListNamespaceTable(ctx, clientset, namespaces, listOptions, printer.Print)
+			}
+			// Other resources default to the current namespace like kubectl
+			if namespaces, err = CommandNamespaces(cmd); err != nil {
+				return err
+			}
 
-					default:
-						return fmt.Errorf("unknown resource type: %s", resource)
-					}
+			clusterScoped := IsClusterScoped(resource)
+			list := func(ctx context.Context, namespace string, emit func(*Table) error) error {
+				return ListResources(ctx, clientset, resource, namespace, listOptions, podFilter, emit)
+			}
+			if apiResource != nil {
+				clusterScoped = !apiResource.Namespaced
+				list = func(ctx context.Context, namespace string, emit func(*Table) error) error {
+					return ListGenericResources(ctx, clientset, *apiResource, namespace, listOptions, emit)
 				}
 			}
 
-			return nil
+			switch {
+			case clusterScoped || len(namespaces) == 1:
+				namespace := ""
+				if !clusterScoped {
+					namespace = namespaces[0]
+					PrintHeading("Namespace: %s\n", namespace)
+				}
+				return list(ctx, namespace, printer.Print)
+			case len(namespaces) > 1:
+				if watchChanges {
+					return fmt.Errorf("--watch takes a single namespace, or --all-namespaces")
+				}
+				return ListNamespaces(ctx, namespaces, list, printer, false)
+			default:
+				// One call for the whole cluster when RBAC allows it
+				return ListAllNamespaces(ctx, clientset, list, printer)
+			}
 		},
 	}
 
@@ -315,13 +379,13 @@ func GetCommands() []*cobra.Command {
 				return fmt.Errorf("pod name not specified")
 			}
 
-			clientset, err := GetClientSet(kubeconfig)
+			clientset, err := GetClientSet(ActiveKubeconfig())
 			if err != nil {
 				return err
 			}
 
 			ctx := context.Background()
-			namespaces, err := cmd.Flags().GetStringSlice("namespace")
+			namespaces, err := CommandNamespaces(cmd)
 			if err != nil {
 				return err
 			}
@@ -340,7 +404,7 @@ func GetCommands() []*cobra.Command {
 		},
 	})
 
-	showCmd.AddCommand(&cobra.Command{
+	logsCmd := &cobra.Command{
 		Use:   "logs [pods]",
 		Short: "Show logs from a specific pods",
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -348,40 +412,100 @@ func GetCommands() []*cobra.Command {
 				return fmt.Errorf("pod name not specified")
 			}
 
-			clientset, err := GetClientSet(kubeconfig)
+			follow, err := cmd.Flags().GetBool("follow")
+			if err != nil {
+				return err
+			}
+			tail, err := cmd.Flags().GetInt64("tail")
+			if err != nil {
+				return err
+			}
+			since, err := cmd.Flags().GetDuration("since")
+			if err != nil {
+				return err
+			}
+			sinceTime, err := cmd.Flags().GetString("since-time")
+			if err != nil {
+				return err
+			}
+			container, err := cmd.Flags().GetString("container")
+			if err != nil {
+				return err
+			}
+			allContainers, err := cmd.Flags().GetBool("all-containers")
+			if err != nil {
+				return err
+			}
+			previous, err := cmd.Flags().GetBool("previous")
+			if err != nil {
+				return err
+			}
+			timestamps, err := cmd.Flags().GetBool("timestamps")
+			if err != nil {
+				return err
+			}
+
+			options := &corev1.PodLogOptions{
+				Follow:     follow,
+				Container:  container,
+				Previous:   previous,
+				Timestamps: timestamps,
+			}
+			if container != "" && allContainers {
+				return fmt.Errorf("--container and --all-containers can't be used together")
+			}
+			if since != 0 && sinceTime != "" {
+				return fmt.Errorf("only one of --since and --since-time can be used")
+			}
+			if since < 0 {
+				return fmt.Errorf("--since must be positive")
+			}
+			if since > 0 {
+				// The API takes whole seconds, round up so nothing asked for is left out
+				seconds := int64(math.Ceil(since.Seconds()))
+				options.SinceSeconds = &seconds
+			}
+			if sinceTime != "" {
+				t, err := time.Parse(time.RFC3339, sinceTime)
+				if err != nil {
+					return fmt.Errorf("invalid --since-time, expected RFC3339 like 2024-01-02T15:04:05Z: %v", err)
+				}
+				options.SinceTime = &metav1.Time{Time: t}
+			}
+			if tail >= 0 {
+				options.TailLines = &tail
+			}
+
+			clientset, err := GetClientSet(ActiveKubeconfig())
 			if err != nil {
 				return err
 			}
 
-			ctx := context.Background()
-			namespaces, err := cmd.Flags().GetStringSlice("namespace")
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer stop()
+			namespaces, err := CommandNamespaces(cmd)
 			if err != nil {
 				return err
 			}
 
+			var sources []LogSource
 			for _, namespace := range namespaces {
 				for _, pod := range args {
-					// Get logs from the pod
-					req := clientset.CoreV1().Pods(namespace).GetLogs(pod, &corev1.PodLogOptions{})
-					stream, err := req.Stream(ctx)
+					podSources, err := LogSources(ctx, clientset, namespace, pod, options, allContainers)
 					if err != nil {
 						return err
 					}
-					defer stream.Close()
-
-					// Print the logs
-					buf := new(bytes.Buffer)
-					_, err = io.Copy(buf, stream)
-					if err != nil {
-						return err
-					}
-					fmt.Printf("Logs from pod %s:\n%s\n", pod, buf.String())
+					sources = append(sources, podSources...)
 				}
 			}
+			if len(sources) == 0 {
+				return nil
+			}
 
-			return nil
+			return StreamLogs(ctx, clientset, sources, *options, os.Stdout)
 		},
-	})
+	}
+	showCmd.AddCommand(logsCmd)
 
 	showCmd.AddCommand(&cobra.Command{
 		Use:   "port [pods]",
@@ -393,13 +517,7 @@ func GetCommands() []*cobra.Command {
 				// Print the list of context names
 				fmt.Println("No available contexts!")
 			} else {
-				// Get the map of context name to context config
-				config, err := clientcmd.NewDefaultClientConfigLoadingRules().Load()
-				if err != nil {
-					return err
-				}
-
-				restConfig, err := clientcmd.NewDefaultClientConfig(*config, &clientcmd.ConfigOverrides{}).ClientConfig()
+				restConfig, err := GetRestConfig(ActiveKubeconfig())
 				if err != nil {
 					return err
 				}
@@ -412,13 +530,13 @@ func GetCommands() []*cobra.Command {
 					return fmt.Errorf("pod name not specified")
 				}
 
-				clientset, err := GetClientSet(kubeconfig)
+				clientset, err := GetClientSet(ActiveKubeconfig())
 				if err != nil {
 					return err
 				}
 
 				ctx := context.Background()
-				namespaces, err := cmd.Flags().GetStringSlice("namespace")
+				namespaces, err := CommandNamespaces(cmd)
 				if err != nil {
 					return err
 				}
@@ -500,7 +618,7 @@ func GetCommands() []*cobra.Command {
 				return fmt.Errorf("node name not specified")
 			}
 
-			clientset, err := GetClientSet(kubeconfig)
+			clientset, err := GetClientSet(ActiveKubeconfig())
 			if err != nil {
 				return err
 			}
@@ -522,45 +640,307 @@ func GetCommands() []*cobra.Command {
 		},
 	})
 
+	apiResourcesCmd := &cobra.Command{
+		Use:   "api-resources",
+		Short: "List the resource types the cluster serves, CRDs included",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			group, err := cmd.Flags().GetString("api-group")
+			if err != nil {
+				return err
+			}
+			clientset, err := GetClientSet(ActiveKubeconfig())
+			if err != nil {
+				return err
+			}
+			resources, err := ServerResources(clientset.Discovery())
+			if err != nil {
+				return err
+			}
+			ShowAPIResources(resources, group)
+			return nil
+		},
+	}
+
+	schemaCmd := &cobra.Command{
+		Use:   "schema <resource>[.field.path]",
+		Short: "Show the fields of a resource from the cluster's OpenAPI schema",
+		Long:  "Show the type, description and fields of a resource or one of its fields from the cluster's OpenAPI v3 schema, e.g. k8c schema deploy.spec.strategy or k8c schema certificates.cert-manager.io.spec",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			clientset, err := GetClientSet(ActiveKubeconfig())
+			if err != nil {
+				return err
+			}
+			explained, err := ExplainField(clientset.Discovery(), args[0])
+			if err != nil {
+				return err
+			}
+			return ShowFieldSchema(explained)
+		},
+	}
+
+	uiCmd := &cobra.Command{
+		Use:   "ui",
+		Short: "Full-screen dashboard of contexts, namespaces and resources",
+		Long:  "Browse contexts, namespaces and resources in a full-screen dashboard that updates live. Enter drills down from a workload to its pods and from a pod to its logs; d describes a pod, l follows its logs, p port-forwards to it",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if IsStructuredOutput() {
+				return fmt.Errorf("ui only supports -o wide")
+			}
+			if !IsInteractive() {
+				return fmt.Errorf("ui needs a terminal")
+			}
+			return RunDashboard(ActiveKubeconfig())
+		},
+	}
+
 	switchContextCmd := &cobra.Command{
-		Use:   "switch",
+		Use:   "switch [context]",
 		Short: "Switch to different context",
+		Long:  "Switch to different context by exact name, unique prefix or fuzzy match. Prompts when the name is missing or ambiguous. Use '-' to switch back to the previous context",
+		Args:  cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			InitConfig()
+			if err := InitConfig(); err != nil {
+				return err
+			}
 
 			// Get the map of context name to context config
 			config, err := clientcmd.Load(configBytes)
 			if err != nil {
 				return err
 			}
-			var contextNames []string
-			for contextName := range config.Contexts {
-				contextNames = append(contextNames, contextName)
+
+			query := ""
+			if len(args) > 0 {
+				query = args[0]
 			}
+			return SwitchContext(query, config)
+		},
+	}
 
-			SelectedConfig(contextNames, config)
+	doctorCmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Check the kubeconfig for broken references, unreadable files and expired credentials",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			fix, err := cmd.Flags().GetBool("fix")
+			if err != nil {
+				return err
+			}
+			return RunDoctor(ActiveKubeconfig(), fix)
+		},
+	}
+
+	contextCmd := &cobra.Command{
+		Use:     "context",
+		Aliases: []string{"ctx"},
+		Short:   "Manage individual contexts (rename, delete, export)",
+	}
+
+	contextCmd.AddCommand(&cobra.Command{
+		Use:   "rename [old] [new]",
+		Short: "Rename a context",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return RenameContext(ActiveKubeconfig(), args[0], args[1])
+		},
+	})
+
+	contextDeleteCmd := &cobra.Command{
+		Use:   "delete [context]",
+		Short: "Delete a context and optionally the cluster and user only it referenced",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			prune, err := cmd.Flags().GetBool("prune")
+			if err != nil {
+				return err
+			}
+			return DeleteContext(ActiveKubeconfig(), args[0], prune)
+		},
+	}
+	contextCmd.AddCommand(contextDeleteCmd)
+
+	contextExportCmd := &cobra.Command{
+		Use:   "export [context]",
+		Short: "Print a self-contained kubeconfig for a single context",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			flatten, err := cmd.Flags().GetBool("flatten")
+			if err != nil {
+				return err
+			}
+			exported, err := ExportContext(ActiveKubeconfig(), args[0], flatten)
+			if err != nil {
+				return err
+			}
+			fmt.Print(string(exported))
 			return nil
 		},
 	}
+	contextCmd.AddCommand(contextExportCmd)
+
+	namespaceCmd := &cobra.Command{
+		Use:   "ns [namespace]",
+		Short: "Switch the default namespace of the current context",
+		Long:  "Switch the default namespace of the current context. Prompts when no namespace is given. Use '-' to switch back to the previous namespace",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			query := ""
+			if len(args) > 0 {
+				query = args[0]
+			}
+			return SwitchNamespace(ActiveKubeconfig(), query)
+		},
+	}
+
+	shellCmd := &cobra.Command{
+		Use:   "shell [context]",
+		Short: "Start a subshell isolated to one context",
+		Long:  "Start a subshell with KUBECONFIG pointing to a temporary kubeconfig that only holds the given context. Other terminals are not affected and the file is removed on exit",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return StartShell(ActiveKubeconfig(), args[0])
+		},
+	}
+
+	envCmd := &cobra.Command{
+		Use:   "env [context]",
+		Short: "Print shell exports that isolate this shell to one context",
+		Long:  "Print shell exports that isolate this shell to one context, use with: eval \"$(k8c env <context>)\". The context is kept in ~/.kube/k8c-sessions, one file per context that every call refreshes. The files hold credentials, remove the directory to clean them up",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			shell, err := cmd.Flags().GetString("shell")
+			if err != nil {
+				return err
+			}
+			return PrintSessionEnv(ActiveKubeconfig(), args[0], shell)
+		},
+	}
+
+	historyCmd := &cobra.Command{
+		Use:   "history",
+		Short: "Show the most recent context switches",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			limit, err := cmd.Flags().GetInt("limit")
+			if err != nil {
+				return err
+			}
+			return ShowHistory(limit)
+		},
+	}
+
+	backupCmd := &cobra.Command{
+		Use:   "backup",
+		Short: "Manage kubeconfig backups",
+		Long:  "Manage kubeconfig backups taken automatically before every write (" + BackupDir() + ")",
+	}
+
+	backupCmd.AddCommand(&cobra.Command{
+		Use:   "list",
+		Short: "List available kubeconfig backups",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			backups, err := ListBackups()
+			if err != nil {
+				return err
+			}
+			ShowBackupList(backups)
+			return nil
+		},
+	})
 
-	getCmd.PersistentFlags().StringSliceP("namespace", "n", []string{}, "Namespaces to filter resources by (comma-separated)")
+	restoreCmd := &cobra.Command{
+		Use:   "restore [id]",
+		Short: "Restore a kubeconfig backup",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return RestoreBackup(args[0])
+		},
+	}
 
+	getCmd.PersistentFlags().StringSliceP("namespace", "n", []string{}, "Namespaces to filter resources by (comma-separated, default: the current namespace)")
+	getCmd.Flags().BoolP("all-namespaces", "A", false, "List resources in every namespace")
+	getCmd.PersistentFlags().StringVarP(&loadFile, "file", "f", "", "Using spesific kubeconfig file")
+	getCmd.Flags().StringP("selector", "l", "", "Label selector to filter on, e.g. -l app=web,tier!=cache")
+	getCmd.Flags().String("field-selector", "", "Field selector to filter on, e.g. --field-selector status.phase=Running")
+	getCmd.Flags().String("status", "", "Only show pods with this status, e.g. CrashLoopBackOff")
+	getCmd.Flags().String("node", "", "Only show pods scheduled on this node")
+	getCmd.Flags().String("image", "", "Only show pods with an image containing this text")
+	getCmd.Flags().BoolVar(&revealSecrets, "reveal", false, "Print decoded secret values (asks for confirmation)")
+	getCmd.Flags().BoolP("watch", "w", false, "After listing, keep printing rows as resources are added, modified or deleted")
+	getCmd.Flags().Int64("chunk-size", DefaultChunkSize, "Fetch large lists in pages of this many items, 0 fetches everything at once")
+
+	mergeCmd.Flags().StringVar(&mergeStrategy, "strategy", string(MergeKeepLast), "Conflict strategy for entries with the same name (fail, keep-first, keep-last, prefix-file, prefix-cluster)")
+
+	mergeCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show the merged kubeconfig as a diff without writing it")
+	splitCmd.Flags().String("out-dir", ".", "Directory to write the per-context kubeconfig files to")
+	splitCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show the files that would be written as diffs without writing them")
+	loadCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show the context change as a diff without writing it")
+	restoreCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show the restore as a diff without writing it")
+	switchContextCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show the context change as a diff without writing it")
+
+	listContextsCmd.Flags().Bool("check", false, "Check connectivity and authentication of every context")
+	listContextsCmd.Flags().Duration("timeout", DefaultCheckTimeout, "Timeout per context for --check")
 	listContextsCmd.Flags().StringVarP(&loadFile, "file", "f", "", "Using spesific kubeconfig file")
 
 	// Add the namespace flag to the show command
-	showCmd.PersistentFlags().StringSliceP("namespace", "n", []string{}, "Namespace to use. Use once for each namespace (default: all namespaces)")
+	showCmd.PersistentFlags().StringSliceP("namespace", "n", []string{}, "Namespace to use. Use once for each namespace (default: the current namespace)")
 	showCmd.Flags().StringVarP(&loadFile, "file", "f", "", "Using spesific kubeconfig file")
+	logsCmd.Flags().BoolP("follow", "f", false, "Keep streaming the logs as they are written")
+	logsCmd.Flags().Int64("tail", -1, "Lines of recent logs to show, -1 shows all")
+	logsCmd.Flags().Duration("since", 0, "Only show logs newer than a relative duration like 5s, 2m or 3h")
+	logsCmd.Flags().String("since-time", "", "Only show logs after a timestamp (RFC3339)")
+	logsCmd.Flags().StringP("container", "c", "", "Container to show logs from (default: the pod's default container)")
+	logsCmd.Flags().Bool("all-containers", false, "Show logs from every container of the pods")
+	logsCmd.Flags().BoolP("previous", "p", false, "Show logs of the previous, terminated container")
+	logsCmd.Flags().Bool("timestamps", false, "Prefix every line with its timestamp")
+
+	doctorCmd.Flags().Bool("fix", false, "Apply safe repairs (dangling current-context, unreferenced clusters and users)")
+	doctorCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show the repairs as diffs without writing them")
+	doctorCmd.Flags().StringVarP(&loadFile, "file", "f", "", "Using spesific kubeconfig file")
+
+	contextCmd.PersistentFlags().StringVarP(&loadFile, "file", "f", "", "Using spesific kubeconfig file")
+	contextCmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "Show the changes as diffs without writing them")
+	contextDeleteCmd.Flags().Bool("prune", false, "Also remove clusters and users no longer referenced by any context")
+	contextExportCmd.Flags().Bool("flatten", false, "Embed referenced certificate and key files into the output")
+
+	namespaceCmd.Flags().StringVarP(&loadFile, "file", "f", "", "Using spesific kubeconfig file")
+	namespaceCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show the namespace change as a diff without writing it")
+
+	shellCmd.Flags().StringVarP(&loadFile, "file", "f", "", "Using spesific kubeconfig file")
+	envCmd.Flags().StringVarP(&loadFile, "file", "f", "", "Using spesific kubeconfig file")
+	envCmd.Flags().String("shell", "sh", "Shell syntax for the exports (sh, fish, powershell)")
+
+	historyCmd.Flags().IntP("limit", "l", DefaultHistory, "Number of switches to show")
+
+	apiResourcesCmd.Flags().String("api-group", "", "Only show resources of this API group")
+	apiResourcesCmd.Flags().StringVarP(&loadFile, "file", "f", "", "Using spesific kubeconfig file")
+	schemaCmd.Flags().StringVarP(&loadFile, "file", "f", "", "Using spesific kubeconfig file")
+	uiCmd.Flags().StringVarP(&loadFile, "file", "f", "", "Using spesific kubeconfig file")
 
 	switchContextCmd.Flags().StringVarP(&loadFile, "file", "f", "", "Using spesific kubeconfig file")
 
-	rootCmd := &cobra.Command{Use: "k8c"}
-	rootCmd.PersistentFlags().StringVar(&kubeconfig, "kubeconfig", kubeconfig, "Path to kubeconfig file")
-
-	rootCmd.AddCommand(versionCmd, getCmd, listContextsCmd, loadCmd, mergeCmd, showCmd, switchContextCmd)
-
-	if err := rootCmd.Execute(); err != nil {
-		logrus.Fatalf("error executing command: %v", err)
+	rootCmd := &cobra.Command{
+		Use:  "k8c",
+		Args: cobra.MaximumNArgs(1),
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return ValidateOutputFormat()
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// "k8c -" toggles back to the previous context, like "cd -"
+			if len(args) == 1 && args[0] == "-" {
+				return switchContextCmd.RunE(switchContextCmd, args)
+			}
+			if len(args) == 1 {
+				return fmt.Errorf("unknown command %q for %q", args[0], cmd.CommandPath())
+			}
+			return cmd.Help()
+		},
 	}
+	rootCmd.PersistentFlags().StringVar(&kubeconfig, "kubeconfig", "", "Path to kubeconfig file (default $KUBECONFIG or ~/.kube/config)")
+	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", "", "Output format: json, yaml, wide, name or custom-columns=<NAME>:<JSONPATH>,...")
+
+	rootCmd.AddCommand(versionCmd, getCmd, listContextsCmd, loadCmd, mergeCmd, splitCmd, showCmd, switchContextCmd, contextCmd, doctorCmd, namespaceCmd, shellCmd, envCmd, historyCmd, backupCmd, restoreCmd, apiResourcesCmd, schemaCmd, uiCmd)
 
-	return []*cobra.Command{versionCmd, getCmd, listContextsCmd, loadCmd, mergeCmd, showCmd, switchContextCmd}
+	return rootCmd, []*cobra.Command{versionCmd, getCmd, listContextsCmd, loadCmd, mergeCmd, splitCmd, showCmd, switchContextCmd, contextCmd, doctorCmd, namespaceCmd, shellCmd, envCmd, historyCmd, backupCmd, restoreCmd, apiResourcesCmd, schemaCmd, uiCmd}
 }
@@ -0,0 +1,301 @@
+package features
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+type MergeStrategy string
+
+const (
+	MergeFail          MergeStrategy = "fail"
+	MergeKeepFirst     MergeStrategy = "keep-first"
+	MergeKeepLast      MergeStrategy = "keep-last"
+	MergePrefixFile    MergeStrategy = "prefix-file"
+	MergePrefixCluster MergeStrategy = "prefix-cluster"
+)
+
+var MergeStrategies = []MergeStrategy{
+	MergeFail,
+	MergeKeepFirst,
+	MergeKeepLast,
+	MergePrefixFile,
+	MergePrefixCluster,
+}
+
+type MergeConflict struct {
+	Kind       string   `json:"kind"`
+	Name       string   `json:"name"`
+	Sources    []string `json:"sources"`
+	Resolution string   `json:"resolution"`
+}
+
+func ParseMergeStrategy(value string) (MergeStrategy, error) {
+	for _, strategy := range MergeStrategies {
+		if string(strategy) == value {
+			return strategy, nil
+		}
+	}
+	var names []string
+	for _, strategy := range MergeStrategies {
+		names = append(names, string(strategy))
+	}
+	return "", fmt.Errorf("unknown merge strategy: %s (available: %s)", value, strings.Join(names, ", "))
+}
+
+// MergeConfigsWithStrategy merges the configs in order, resolving entries that
+// share a name but differ in content according to strategy. sources holds the
+// file each config was loaded from and is used for reporting and prefixing.
+func MergeConfigsWithStrategy(configs []*clientcmdapi.Config, sources []string, strategy MergeStrategy) (*clientcmdapi.Config, []MergeConflict, error) {
+	if strategy == "" {
+		strategy = MergeKeepLast
+	}
+
+	newConfig := &clientcmdapi.Config{
+		Kind:       "Config",
+		APIVersion: "v1",
+		Clusters:   make(map[string]*clientcmdapi.Cluster),
+		AuthInfos:  make(map[string]*clientcmdapi.AuthInfo),
+		Contexts:   make(map[string]*clientcmdapi.Context),
+	}
+
+	clusterOwner := make(map[string]string)
+	authOwner := make(map[string]string)
+	contextOwner := make(map[string]string)
+	var conflicts []MergeConflict
+
+	for i, config := range configs {
+		source := fmt.Sprintf("config[%d]", i)
+		if i < len(sources) {
+			source = sources[i]
+		}
+
+		clusterRenames := make(map[string]string)
+		authRenames := make(map[string]string)
+		contextRenames := make(map[string]string)
+
+		for _, name := range sortedKeys(config.Clusters) {
+			cluster := config.Clusters[name]
+			existing, found := newConfig.Clusters[name]
+			if !found {
+				newConfig.Clusters[name] = cluster
+				clusterOwner[name] = source
+				continue
+			}
+			if sameCluster(existing, cluster) {
+				continue
+			}
+
+			conflict := MergeConflict{Kind: "cluster", Name: name, Sources: []string{clusterOwner[name], source}}
+			switch strategy {
+			case MergeKeepFirst:
+				conflict.Resolution = "kept " + clusterOwner[name]
+			case MergeKeepLast:
+				newConfig.Clusters[name] = cluster
+				clusterOwner[name] = source
+				conflict.Resolution = "kept " + source
+			case MergePrefixFile, MergePrefixCluster:
+				prefix := filePrefix(source)
+				if strategy == MergePrefixCluster {
+					prefix = serverPrefix(cluster.Server, prefix)
+				}
+				renamed := uniqueName(prefix+"-"+name, newConfig.Clusters)
+				newConfig.Clusters[renamed] = cluster
+				clusterOwner[renamed] = source
+				clusterRenames[name] = renamed
+				conflict.Resolution = "renamed to " + renamed
+			}
+			conflicts = append(conflicts, conflict)
+		}
+
+		for _, name := range sortedKeys(config.AuthInfos) {
+			authInfo := config.AuthInfos[name]
+			existing, found := newConfig.AuthInfos[name]
+			if !found {
+				newConfig.AuthInfos[name] = authInfo
+				authOwner[name] = source
+				continue
+			}
+			if sameAuthInfo(existing, authInfo) {
+				continue
+			}
+
+			conflict := MergeConflict{Kind: "user", Name: name, Sources: []string{authOwner[name], source}}
+			switch strategy {
+			case MergeKeepFirst:
+				conflict.Resolution = "kept " + authOwner[name]
+			case MergeKeepLast:
+				newConfig.AuthInfos[name] = authInfo
+				authOwner[name] = source
+				conflict.Resolution = "kept " + source
+			case MergePrefixFile, MergePrefixCluster:
+				prefix := filePrefix(source)
+				if strategy == MergePrefixCluster {
+					prefix = clusterPrefixForUser(config, name, clusterRenames, prefix)
+				}
+				renamed := uniqueName(prefix+"-"+name, newConfig.AuthInfos)
+				newConfig.AuthInfos[renamed] = authInfo
+				authOwner[renamed] = source
+				authRenames[name] = renamed
+				conflict.Resolution = "renamed to " + renamed
+			}
+			conflicts = append(conflicts, conflict)
+		}
+
+		for _, name := range sortedKeys(config.Contexts) {
+			// Copy the context so rewriting references never touches the source config
+			context := *config.Contexts[name]
+			if renamed, ok := clusterRenames[context.Cluster]; ok {
+				context.Cluster = renamed
+			}
+			if renamed, ok := authRenames[context.AuthInfo]; ok {
+				context.AuthInfo = renamed
+			}
+
+			existing, found := newConfig.Contexts[name]
+			if !found {
+				newConfig.Contexts[name] = &context
+				contextOwner[name] = source
+				continue
+			}
+			if sameContext(existing, &context) {
+				continue
+			}
+
+			conflict := MergeConflict{Kind: "context", Name: name, Sources: []string{contextOwner[name], source}}
+			switch strategy {
+			case MergeKeepFirst:
+				conflict.Resolution = "kept " + contextOwner[name]
+			case MergeKeepLast:
+				newConfig.Contexts[name] = &context
+				contextOwner[name] = source
+				conflict.Resolution = "kept " + source
+			case MergePrefixFile, MergePrefixCluster:
+				prefix := filePrefix(source)
+				if strategy == MergePrefixCluster && context.Cluster != "" {
+					prefix = context.Cluster
+				}
+				renamed := uniqueName(prefix+"-"+name, newConfig.Contexts)
+				newConfig.Contexts[renamed] = &context
+				contextOwner[renamed] = source
+				contextRenames[name] = renamed
+				conflict.Resolution = "renamed to " + renamed
+			}
+			conflicts = append(conflicts, conflict)
+		}
+
+		if newConfig.CurrentContext == "" && config.CurrentContext != "" {
+			newConfig.CurrentContext = config.CurrentContext
+			if renamed, ok := contextRenames[config.CurrentContext]; ok {
+				newConfig.CurrentContext = renamed
+			}
+		}
+	}
+
+	if strategy == MergeFail && len(conflicts) > 0 {
+		for i := range conflicts {
+			conflicts[i].Resolution = "unresolved"
+		}
+		return nil, conflicts, fmt.Errorf("found %d conflicting entries, choose a merge strategy to resolve them", len(conflicts))
+	}
+
+	return newConfig, conflicts, nil
+}
+
+func ShowConflictReport(conflicts []MergeConflict) error {
+	if len(conflicts) == 0 && !IsStructuredOutput() {
+		fmt.Println("No conflicts found")
+		return nil
+	}
+
+	PrintHeading("Found %d conflict(s):\n", len(conflicts))
+	table := NewTable("conflict",
+		Column{Header: "Kind"},
+		Column{Header: "Name"},
+		Column{Header: "Sources"},
+		Column{Header: "Resolution"},
+	)
+	for _, conflict := range conflicts {
+		table.Append(conflict.Name, conflict,
+			conflict.Kind,
+			conflict.Name,
+			strings.Join(conflict.Sources, ", "),
+			conflict.Resolution,
+		)
+	}
+	return PrintTable(table)
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// LocationOfOrigin is set by the loader to the file an entry came from, so it
+// is ignored when deciding whether two entries are really the same.
+func sameCluster(a, b *clientcmdapi.Cluster) bool {
+	x, y := *a, *b
+	x.LocationOfOrigin, y.LocationOfOrigin = "", ""
+	return reflect.DeepEqual(x, y)
+}
+
+func sameAuthInfo(a, b *clientcmdapi.AuthInfo) bool {
+	x, y := *a, *b
+	x.LocationOfOrigin, y.LocationOfOrigin = "", ""
+	return reflect.DeepEqual(x, y)
+}
+
+func sameContext(a, b *clientcmdapi.Context) bool {
+	x, y := *a, *b
+	x.LocationOfOrigin, y.LocationOfOrigin = "", ""
+	return reflect.DeepEqual(x, y)
+}
+
+func filePrefix(source string) string {
+	base := filepath.Base(source)
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
+
+func serverPrefix(server string, fallback string) string {
+	u, err := url.Parse(server)
+	if err != nil || u.Hostname() == "" {
+		return fallback
+	}
+	return strings.SplitN(u.Hostname(), ".", 2)[0]
+}
+
+func clusterPrefixForUser(config *clientcmdapi.Config, authInfo string, clusterRenames map[string]string, fallback string) string {
+	for _, name := range sortedKeys(config.Contexts) {
+		context := config.Contexts[name]
+		if context.AuthInfo != authInfo || context.Cluster == "" {
+			continue
+		}
+		if renamed, ok := clusterRenames[context.Cluster]; ok {
+			return renamed
+		}
+		return context.Cluster
+	}
+	return fallback
+}
+
+func uniqueName[T any](name string, existing map[string]T) string {
+	if _, found := existing[name]; !found {
+		return name
+	}
+	for i := 2; ; i++ {
+		candidate := fmt.Sprintf("%s-%d", name, i)
+		if _, found := existing[candidate]; !found {
+			return candidate
+		}
+	}
+}
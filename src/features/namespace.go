@@ -0,0 +1,150 @@
+package features
+
+import (
+	"context"
+	"fmt"
+
+	survey "github.com/AlecAivazis/survey/v2"
+	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+const DefaultNamespace = "default"
+
+// CurrentNamespace returns the namespace of the current context, the one
+// "k8c ns" switches, or "default" when the context sets none.
+func CurrentNamespace(kubeconfigPath string) (string, error) {
+	namespace, _, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(LoadingRules(kubeconfigPath), &clientcmd.ConfigOverrides{}).Namespace()
+	return namespace, err
+}
+
+// CommandNamespaces returns the namespaces given with -n, falling back to
+// the current namespace. --all-namespaces, on commands that have it, returns
+// none so every namespace is listed.
+func CommandNamespaces(cmd *cobra.Command) ([]string, error) {
+	namespaces, err := cmd.Flags().GetStringSlice("namespace")
+	if err != nil {
+		return nil, err
+	}
+	if cmd.Flags().Lookup("all-namespaces") != nil {
+		allNamespaces, err := cmd.Flags().GetBool("all-namespaces")
+		if err != nil {
+			return nil, err
+		}
+		if allNamespaces {
+			if len(namespaces) > 0 {
+				return nil, fmt.Errorf("-n and --all-namespaces can't be used together")
+			}
+			return nil, nil
+		}
+	}
+	if len(namespaces) > 0 {
+		return namespaces, nil
+	}
+
+	namespace, err := CurrentNamespace(ActiveKubeconfig())
+	if err != nil {
+		return nil, err
+	}
+	return []string{namespace}, nil
+}
+
+func SwitchNamespace(kubeconfigPath string, query string) error {
+	pathOptions := PathOptions(kubeconfigPath)
+	config, err := pathOptions.GetStartingConfig()
+	if err != nil {
+		return err
+	}
+
+	contextName := config.CurrentContext
+	kubeContext, ok := config.Contexts[contextName]
+	if !ok {
+		return fmt.Errorf("current context not found in kubeconfig: %s", contextName)
+	}
+
+	current := kubeContext.Namespace
+	if current == "" {
+		current = DefaultNamespace
+	}
+
+	clientset, err := GetClientSet(kubeconfigPath)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	namespace := query
+	switch query {
+	case "-":
+		namespace, err = PreviousNamespace(contextName)
+		if err != nil {
+			return err
+		}
+
+	case "":
+		nsList, err := clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return err
+		}
+		if !IsInteractive() {
+			PrintHeading("Context: %s\nNamespace: %s\n", contextName, current)
+			ShowNamespaceByFilter(nsList)
+			return nil
+		}
+
+		var names []string
+		for _, ns := range nsList.Items {
+			names = append(names, ns.Name)
+		}
+		prompt := &survey.Select{
+			Message: "Select a namespace",
+			Options: names,
+		}
+		// survey rejects a default that isn't an option, the current namespace
+		// may have been deleted or be hidden by RBAC
+		for _, name := range names {
+			if name == current {
+				prompt.Default = current
+			}
+		}
+		if err := survey.AskOne(prompt, &namespace, survey.WithValidator(survey.Required)); err != nil {
+			return err
+		}
+	}
+
+	// Users without cluster-wide namespace access can still switch by name
+	if _, err := clientset.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{}); err != nil {
+		if apierrors.IsNotFound(err) {
+			return fmt.Errorf("namespace not found: %s", namespace)
+		}
+		fmt.Printf("> Can't verify namespace %s: %v\n", namespace, err)
+	}
+
+	// The context is written back to the file that defines it
+	target := ContextOrigin(kubeconfigPath, config, contextName)
+
+	kubeContext.Namespace = namespace
+	if dryRun {
+		return PreviewFileChange(target, func(config *clientcmdapi.Config) {
+			if kubeContext, ok := config.Contexts[contextName]; ok {
+				kubeContext.Namespace = namespace
+			}
+		})
+	}
+
+	if err := ModifyKubeconfig(pathOptions, config, target); err != nil {
+		fmt.Printf("\n> Failed to change namespace: %s\n", namespace)
+		return err
+	}
+	fmt.Printf("\n> Successfully change namespace of context %s to: %s\n", contextName, namespace)
+
+	if namespace != current {
+		if err := RecordNamespace(contextName, current, namespace); err != nil {
+			fmt.Printf("> Can't record namespace history: %v\n", err)
+		}
+	}
+	return nil
+}
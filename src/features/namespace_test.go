@@ -0,0 +1,99 @@
+package features
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testPod = `{"kind":"Pod","apiVersion":"v1","metadata":{"name":"web","namespace":"team"},"spec":{"containers":[{"name":"app","image":"nginx"}]}}`
+
+// namespaceServer answers for the pod web in namespace team and records the
+// paths it is asked for.
+func namespaceServer(t *testing.T) (*httptest.Server, func() []string) {
+	var mu sync.Mutex
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, r.URL.Path)
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v1/pods", "/api/v1/namespaces/team/pods":
+			w.Write([]byte(`{"kind":"PodList","apiVersion":"v1","metadata":{},"items":[` + testPod + `]}`))
+		case "/api/v1/namespaces/team/pods/web":
+			w.Write([]byte(testPod))
+		case "/api/v1/namespaces/team/pods/web/log":
+			w.Header().Set("Content-Type", "text/plain")
+			w.Write([]byte("started\n"))
+		default:
+			statusHandler(http.StatusNotFound, "NotFound")(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), paths...)
+	}
+}
+
+// writeNamespaceKubeconfig writes a kubeconfig whose current context is in
+// namespace team, as "k8c ns team" leaves it.
+func writeNamespaceKubeconfig(t *testing.T, server string) string {
+	path := filepath.Join(t.TempDir(), "config")
+	require.NoError(t, os.WriteFile(path, []byte(`apiVersion: v1
+kind: Config
+clusters:
+- name: test
+  cluster:
+    server: `+server+`
+contexts:
+- name: test
+  context:
+    cluster: test
+    user: test
+    namespace: team
+current-context: test
+users:
+- name: test
+  user: {}
+`), 0600))
+	return path
+}
+
+func TestCurrentNamespace(t *testing.T) {
+	server, _ := namespaceServer(t)
+	namespace, err := CurrentNamespace(writeNamespaceKubeconfig(t, server.URL))
+	require.NoError(t, err)
+	assert.Equal(t, "team", namespace)
+}
+
+func TestCommandsUseCurrentNamespace(t *testing.T) {
+	tests := []struct {
+		args []string
+		path string
+	}{
+		{args: []string{"get", "po"}, path: "/api/v1/namespaces/team/pods"},
+		{args: []string{"get", "po", "-A"}, path: "/api/v1/pods"},
+		{args: []string{"show", "po", "web"}, path: "/api/v1/namespaces/team/pods/web"},
+		{args: []string{"show", "logs", "web"}, path: "/api/v1/namespaces/team/pods/web/log"},
+	}
+	for _, test := range tests {
+		server, paths := namespaceServer(t)
+		kubeconfigPath := writeNamespaceKubeconfig(t, server.URL)
+
+		rootCmd, _ := NewCommands()
+		rootCmd.SetArgs(append(test.args, "--kubeconfig", kubeconfigPath))
+		require.NoError(t, rootCmd.Execute(), test.args)
+		assert.Contains(t, paths(), test.path, test.args)
+	}
+}
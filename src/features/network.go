@@ -1,30 +1,30 @@
 package features
 
 import (
-	"context"
 	"fmt"
-	"os"
 	"strconv"
 	"strings"
 	"time"
 
-	"github.com/olekukonko/tablewriter"
 	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-func ShowServiceByFilter(services *corev1.ServiceList) {
-	table := tablewriter.NewWriter(os.Stdout)
-	table.Header([]string{
-		"NAME",
-		"TYPE",
-		"CLUSTER-IP",
-		"EXTERNAL-IP(S)",
-		"PORT(S)",
-		"AGE",
-	})
-
-	for _, service := range services.Items {
+func ServiceTable(services *corev1.ServiceList) *Table {
+	table := NewTable("service",
+		Column{Header: "NAME"},
+		Column{Header: "TYPE"},
+		Column{Header: "CLUSTER-IP"},
+		Column{Header: "EXTERNAL-IP(S)"},
+		Column{Header: "PORT(S)"},
+		Column{Header: "AGE"},
+		Column{Header: "SELECTOR", Wide: true},
+	)
+
+	for i := range services.Items {
+		service := &services.Items[i]
 		var externalIPs string
 		if service.Spec.Type == corev1.ServiceTypeLoadBalancer && len(service.Status.LoadBalancer.Ingress) > 0 {
 			if service.Status.LoadBalancer.Ingress[0].IP != "" {
@@ -63,75 +63,402 @@ func ShowServiceByFilter(services *corev1.ServiceList) {
 			ports[i] += "/" + protocolName
 		}
 
-		table.Append([]string{
+		table.Append(service.Name, service,
 			service.Name,
 			string(service.Spec.Type),
 			service.Spec.ClusterIP,
 			externalIPs,
 			strings.Join(ports, ", "),
 			age,
-		})
+			formatLabels(service.Spec.Selector),
+		)
 	}
-	table.Render()
+	return table
 }
 
-func ShowEndpointByFilter(endpoints *corev1.EndpointsList) {
-	table := tablewriter.NewWriter(os.Stdout)
-	table.Header([]string{
-		"NAME",
-		"ENDPOINTS TARGET",
-		"ENDPOINTS PORT(S)",
-		// "ENDPOINTS NAME",
-		"AGE",
-	})
-
-	for _, ep := range endpoints.Items {
-		serviceName := ep.ObjectMeta.Name
-		age := HumanReadableDuration(time.Since(ep.ObjectMeta.CreationTimestamp.Time))
-
-		for _, subset := range ep.Subsets {
-			addresses := make([]string, len(subset.Addresses))
-			ports := make([]string, len(subset.Ports))
-			for i, addr := range subset.Addresses {
-				target := addr.TargetRef.Name
-				if addr.TargetRef.Kind == "Pod" {
-					pod, err := GetPod(addr.TargetRef.Namespace, target)
-					if err == nil {
-						target = fmt.Sprintf("%s (%s)", target, pod.Status.PodIP)
-					}
-				}
-				addresses[i] = target
+// EndpointSliceTable has one row per service, combining the slices
+// of every address family so dual-stack endpoints show all their addresses.
+// Structured output gets the slices themselves.
+func EndpointSliceTable(slices *discoveryv1.EndpointSliceList) *Table {
+	table := NewTable("endpointslice.discovery.k8s.io",
+		Column{Header: "NAME"},
+		Column{Header: "ADDRESS TYPE"},
+		Column{Header: "PORTS"},
+		Column{Header: "READY"},
+		Column{Header: "ENDPOINTS"},
+		Column{Header: "AGE"},
+		Column{Header: "SLICES", Wide: true},
+	)
+
+	if IsStructuredOutput() {
+		for i := range slices.Items {
+			slice := &slices.Items[i]
+			table.Append(slice.Name, slice)
+		}
+		return table
+	}
+
+	type serviceEndpoints struct {
+		name         string
+		slices       []*discoveryv1.EndpointSlice
+		addressTypes []string
+		ports        []string
+		endpoints    []string
+		addresses    map[string][]string
+		conditions   map[string]string
+		created      time.Time
+	}
+	services := make(map[string]*serviceEndpoints)
+	for i := range slices.Items {
+		slice := &slices.Items[i]
+		name := slice.Labels[discoveryv1.LabelServiceName]
+		if name == "" {
+			name = slice.Name
+		}
+		// Keyed by namespace too, all namespaces can be listed at once
+		key := slice.Namespace + "/" + name
+		service, ok := services[key]
+		if !ok {
+			service = &serviceEndpoints{
+				name:       name,
+				addresses:  make(map[string][]string),
+				conditions: make(map[string]string),
+				created:    slice.CreationTimestamp.Time,
 			}
-			for i, port := range subset.Ports {
-				portNumber := strconv.Itoa(int(port.Port))
-				if int(port.Port) == 0 {
-					portNumber = port.Name
-				}
-				ports[i] = portNumber
+			services[key] = service
+		}
+		service.slices = append(service.slices, slice)
+		service.addressTypes = appendUnique(service.addressTypes, string(slice.AddressType))
+		if slice.CreationTimestamp.Time.Before(service.created) {
+			service.created = slice.CreationTimestamp.Time
+		}
+		for _, port := range slice.Ports {
+			service.ports = appendUnique(service.ports, endpointPort(port))
+		}
+
+		// The same pod shows up once per address family
+		for _, endpoint := range slice.Endpoints {
+			target := strings.Join(endpoint.Addresses, ",")
+			if endpoint.TargetRef != nil {
+				target = endpoint.TargetRef.Name
+			}
+			if _, ok := service.conditions[target]; !ok {
+				service.endpoints = append(service.endpoints, target)
+				service.conditions[target] = endpointConditions(endpoint)
+			}
+			service.addresses[target] = append(service.addresses[target], endpoint.Addresses...)
+		}
+	}
+
+	for _, key := range sortedKeys(services) {
+		service := services[key]
+
+		ready := 0
+		lines := make([]string, 0, len(service.endpoints))
+		for _, target := range service.endpoints {
+			conditions := service.conditions[target]
+			if strings.HasPrefix(conditions, "ready") {
+				ready++
+			}
+			addresses := strings.Join(service.addresses[target], ",")
+			if addresses == target {
+				lines = append(lines, fmt.Sprintf("%s (%s)", addresses, conditions))
+			} else {
+				lines = append(lines, fmt.Sprintf("%s %s (%s)", target, addresses, conditions))
+			}
+		}
+		if len(lines) == 0 {
+			lines = []string{"<none>"}
+		}
+		if len(service.ports) == 0 {
+			service.ports = []string{"<none>"}
+		}
+
+		sliceNames := make([]string, 0, len(service.slices))
+		for _, slice := range service.slices {
+			sliceNames = append(sliceNames, slice.Name)
+		}
+
+		table.Append(service.name, service.slices[0],
+			service.name,
+			strings.Join(service.addressTypes, ", "),
+			strings.Join(service.ports, ", "),
+			fmt.Sprintf("%d/%d", ready, len(service.endpoints)),
+			strings.Join(lines, "\n"),
+			HumanReadableDuration(time.Since(service.created)),
+			strings.Join(sliceNames, "\n"),
+		)
+	}
+	return table
+}
+
+// endpointConditions describes the state of an endpoint with its zone and
+// topology hints. A missing ready condition means ready.
+func endpointConditions(endpoint discoveryv1.Endpoint) string {
+	var parts []string
+	switch {
+	case endpoint.Conditions.Ready == nil || *endpoint.Conditions.Ready:
+		parts = append(parts, "ready")
+	case endpoint.Conditions.Serving != nil && *endpoint.Conditions.Serving:
+		parts = append(parts, "not ready, serving")
+	default:
+		parts = append(parts, "not ready")
+	}
+	if endpoint.Conditions.Terminating != nil && *endpoint.Conditions.Terminating {
+		parts = append(parts, "terminating")
+	}
+	if endpoint.Zone != nil {
+		parts = append(parts, "zone "+*endpoint.Zone)
+	}
+	if endpoint.Hints != nil && len(endpoint.Hints.ForZones) > 0 {
+		var zones []string
+		for _, zone := range endpoint.Hints.ForZones {
+			zones = append(zones, zone.Name)
+		}
+		parts = append(parts, "hints "+strings.Join(zones, ","))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func endpointPort(port discoveryv1.EndpointPort) string {
+	number := "*"
+	if port.Port != nil {
+		number = strconv.Itoa(int(*port.Port))
+	}
+	protocol := string(corev1.ProtocolTCP)
+	if port.Protocol != nil {
+		protocol = string(*port.Protocol)
+	}
+	if port.Name != nil && *port.Name != "" {
+		return fmt.Sprintf("%s:%s/%s", *port.Name, number, protocol)
+	}
+	return fmt.Sprintf("%s/%s", number, protocol)
+}
+
+func appendUnique(values []string, value string) []string {
+	for _, existing := range values {
+		if existing == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
+
+func IngressTable(ingresses *networkingv1.IngressList) *Table {
+	table := NewTable("ingress.networking.k8s.io",
+		Column{Header: "NAME"},
+		Column{Header: "CLASS"},
+		Column{Header: "HOSTS"},
+		Column{Header: "ADDRESS"},
+		Column{Header: "PORTS"},
+		Column{Header: "RULES"},
+		Column{Header: "AGE"},
+		Column{Header: "TLS", Wide: true},
+	)
+
+	for i := range ingresses.Items {
+		ingress := &ingresses.Items[i]
+
+		class := "<none>"
+		if ingress.Spec.IngressClassName != nil {
+			class = *ingress.Spec.IngressClassName
+		}
+
+		var hosts, rules []string
+		for _, rule := range ingress.Spec.Rules {
+			host := rule.Host
+			if host == "" {
+				host = "*"
+			}
+			hosts = append(hosts, host)
+			if rule.HTTP == nil {
+				continue
+			}
+			for _, path := range rule.HTTP.Paths {
+				rules = append(rules, fmt.Sprintf("%s%s -> %s", host, path.Path, ingressBackend(path.Backend)))
+			}
+		}
+		if ingress.Spec.DefaultBackend != nil {
+			rules = append(rules, "default -> "+ingressBackend(*ingress.Spec.DefaultBackend))
+		}
+		if len(hosts) == 0 {
+			hosts = []string{"*"}
+		}
+
+		var addresses []string
+		for _, lb := range ingress.Status.LoadBalancer.Ingress {
+			if lb.IP != "" {
+				addresses = append(addresses, lb.IP)
+			} else if lb.Hostname != "" {
+				addresses = append(addresses, lb.Hostname)
+			}
+		}
+
+		ports := "80"
+		var tls []string
+		for _, entry := range ingress.Spec.TLS {
+			tls = append(tls, fmt.Sprintf("%s (%s)", entry.SecretName, strings.Join(entry.Hosts, ", ")))
+		}
+		if len(tls) > 0 {
+			ports = "80, 443"
+		} else {
+			tls = []string{"<none>"}
+		}
+
+		table.Append(ingress.Name, ingress,
+			ingress.Name,
+			class,
+			strings.Join(hosts, ", "),
+			strings.Join(addresses, ", "),
+			ports,
+			strings.Join(rules, "\n"),
+			HumanReadableDuration(time.Since(ingress.CreationTimestamp.Time)),
+			strings.Join(tls, "\n"),
+		)
+	}
+	return table
+}
+
+func ingressBackend(backend networkingv1.IngressBackend) string {
+	switch {
+	case backend.Service != nil:
+		port := backend.Service.Port.Name
+		if port == "" {
+			port = strconv.Itoa(int(backend.Service.Port.Number))
+		}
+		return backend.Service.Name + ":" + port
+	case backend.Resource != nil:
+		return backend.Resource.Kind + "/" + backend.Resource.Name
+	}
+	return "<none>"
+}
+
+func NetworkPolicyTable(policies *networkingv1.NetworkPolicyList) *Table {
+	table := NewTable("networkpolicy.networking.k8s.io",
+		Column{Header: "NAME"},
+		Column{Header: "POD SELECTOR"},
+		Column{Header: "POLICY TYPES"},
+		Column{Header: "INGRESS"},
+		Column{Header: "EGRESS"},
+		Column{Header: "AGE"},
+	)
+
+	for i := range policies.Items {
+		policy := &policies.Items[i]
+
+		types := NetworkPolicyTypes(policy)
+		var typeNames []string
+		ingress, egress := "-", "-"
+		for _, policyType := range types {
+			typeNames = append(typeNames, string(policyType))
+			switch policyType {
+			case networkingv1.PolicyTypeIngress:
+				ingress = summarizeIngressRules(policy.Spec.Ingress)
+			case networkingv1.PolicyTypeEgress:
+				egress = summarizeEgressRules(policy.Spec.Egress)
 			}
-			table.Append([]string{
-				serviceName,
-				strings.Join(addresses, ", "),
-				strings.Join(ports, ", "),
-				age,
-			})
 		}
+
+		table.Append(policy.Name, policy,
+			policy.Name,
+			selectorOrAll(policy.Spec.PodSelector, "all pods"),
+			strings.Join(typeNames, ", "),
+			ingress,
+			egress,
+			HumanReadableDuration(time.Since(policy.CreationTimestamp.Time)),
+		)
+	}
+	return table
+}
+
+// NetworkPolicyTypes returns the policy types in effect. Without explicit
+// types a policy always affects ingress, and egress only if it has egress
+// rules.
+func NetworkPolicyTypes(policy *networkingv1.NetworkPolicy) []networkingv1.PolicyType {
+	if len(policy.Spec.PolicyTypes) > 0 {
+		return policy.Spec.PolicyTypes
+	}
+	types := []networkingv1.PolicyType{networkingv1.PolicyTypeIngress}
+	if len(policy.Spec.Egress) > 0 {
+		types = append(types, networkingv1.PolicyTypeEgress)
+	}
+	return types
+}
+
+func summarizeIngressRules(rules []networkingv1.NetworkPolicyIngressRule) string {
+	if len(rules) == 0 {
+		return "deny all"
+	}
+	var summary []string
+	for _, rule := range rules {
+		summary = append(summary, fmt.Sprintf("from %s on %s", networkPolicyPeers(rule.From), networkPolicyPorts(rule.Ports)))
 	}
+	return strings.Join(summary, "\n")
+}
 
-	table.Render()
+func summarizeEgressRules(rules []networkingv1.NetworkPolicyEgressRule) string {
+	if len(rules) == 0 {
+		return "deny all"
+	}
+	var summary []string
+	for _, rule := range rules {
+		summary = append(summary, fmt.Sprintf("to %s on %s", networkPolicyPeers(rule.To), networkPolicyPorts(rule.Ports)))
+	}
+	return strings.Join(summary, "\n")
 }
 
-func GetPod(namespace string, name string) (*corev1.Pod, error) {
-	clientset, err := GetClientSet(kubeconfig)
-	if err != nil {
-		return nil, err
+func networkPolicyPeers(peers []networkingv1.NetworkPolicyPeer) string {
+	if len(peers) == 0 {
+		return "anywhere"
+	}
+	var result []string
+	for _, peer := range peers {
+		switch {
+		case peer.IPBlock != nil:
+			block := peer.IPBlock.CIDR
+			if len(peer.IPBlock.Except) > 0 {
+				block += " except " + strings.Join(peer.IPBlock.Except, ", ")
+			}
+			result = append(result, block)
+		case peer.NamespaceSelector != nil && peer.PodSelector != nil:
+			result = append(result, fmt.Sprintf("ns(%s)+pods(%s)",
+				selectorOrAll(*peer.NamespaceSelector, "all"), selectorOrAll(*peer.PodSelector, "all")))
+		case peer.NamespaceSelector != nil:
+			result = append(result, fmt.Sprintf("ns(%s)", selectorOrAll(*peer.NamespaceSelector, "all")))
+		case peer.PodSelector != nil:
+			result = append(result, fmt.Sprintf("pods(%s)", selectorOrAll(*peer.PodSelector, "all")))
+		}
 	}
+	return strings.Join(result, ", ")
+}
 
-	ctx := context.Background()
-	pod, err := clientset.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
-	if err != nil {
-		return nil, err
+func networkPolicyPorts(ports []networkingv1.NetworkPolicyPort) string {
+	if len(ports) == 0 {
+		return "all ports"
+	}
+	var result []string
+	for _, port := range ports {
+		protocol := string(corev1.ProtocolTCP)
+		if port.Protocol != nil {
+			protocol = string(*port.Protocol)
+		}
+		switch {
+		case port.Port == nil:
+			result = append(result, protocol)
+		case port.EndPort != nil:
+			result = append(result, fmt.Sprintf("%s/%s-%d", protocol, port.Port.String(), *port.EndPort))
+		default:
+			result = append(result, fmt.Sprintf("%s/%s", protocol, port.Port.String()))
+		}
 	}
+	return strings.Join(result, ", ")
+}
 
-	return pod, nil
+// selectorOrAll formats selector, using all for the empty selector which
+// matches everything.
+func selectorOrAll(selector metav1.LabelSelector, all string) string {
+	if len(selector.MatchLabels) == 0 && len(selector.MatchExpressions) == 0 {
+		return all
+	}
+	return metav1.FormatLabelSelector(&selector)
 }
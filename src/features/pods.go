@@ -3,6 +3,7 @@ package features
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"net"
 	"os"
 	"sort"
@@ -10,10 +11,10 @@ import (
 	"strings"
 	"time"
 
-	"github.com/olekukonko/tablewriter"
 	yaml "gopkg.in/yaml.v2"
 	v1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func GetContainerImages(pod *corev1.Pod) []string {
@@ -43,28 +44,95 @@ func GetLabels(pod *corev1.Pod) []string {
 	return labels
 }
 
-func ShowPodsByFilter(pods *corev1.PodList) {
-	table := tablewriter.NewWriter(os.Stdout)
-	table.Header([]string{
-		"POD NAME",
-		"READY",
-		"STATUS",
-		"RESTARTS",
-		"AGE",
-		"IMAGE",
-		"NODE",
-	})
+// PodStatus returns the status kubectl shows for a pod: the reason a container
+// is waiting or terminated with (e.g. CrashLoopBackOff), else the pod phase.
+func PodStatus(pod *corev1.Pod) string {
+	if pod.DeletionTimestamp != nil {
+		return "Terminating"
+	}
+	status := string(pod.Status.Phase)
+	if pod.Status.Reason != "" {
+		status = pod.Status.Reason
+	}
+	for _, cs := range pod.Status.InitContainerStatuses {
+		if cs.State.Waiting != nil && cs.State.Waiting.Reason != "" && cs.State.Waiting.Reason != "PodInitializing" {
+			return "Init:" + cs.State.Waiting.Reason
+		}
+	}
+	for _, cs := range pod.Status.ContainerStatuses {
+		if cs.State.Waiting != nil && cs.State.Waiting.Reason != "" {
+			return cs.State.Waiting.Reason
+		}
+		if cs.State.Terminated != nil && cs.State.Terminated.Reason != "" {
+			status = cs.State.Terminated.Reason
+		}
+	}
+	return status
+}
+
+// PodFilter holds the pod filters that have no server-side field selector,
+// they are matched against the same values the pod table shows.
+type PodFilter struct {
+	Status string
+	Node   string
+	Image  string
+}
+
+func (f PodFilter) IsEmpty() bool {
+	return f.Status == "" && f.Node == "" && f.Image == ""
+}
+
+func (f PodFilter) Match(pod *corev1.Pod) bool {
+	if f.Status != "" && !strings.EqualFold(PodStatus(pod), f.Status) {
+		return false
+	}
+	if f.Node != "" && pod.Spec.NodeName != f.Node {
+		return false
+	}
+	if f.Image != "" && !strings.Contains(strings.Join(GetContainerImages(pod), " "), f.Image) {
+		return false
+	}
+	return true
+}
 
+func FilterPods(pods *corev1.PodList, filter PodFilter) *corev1.PodList {
+	if filter.IsEmpty() {
+		return pods
+	}
+	filtered := &corev1.PodList{TypeMeta: pods.TypeMeta, ListMeta: pods.ListMeta}
 	for _, pod := range pods.Items {
+		if filter.Match(&pod) {
+			filtered.Items = append(filtered.Items, pod)
+		}
+	}
+	return filtered
+}
+
+func PodTable(pods *corev1.PodList) *Table {
+	table := NewTable("pod",
+		Column{Header: "POD NAME"},
+		Column{Header: "READY"},
+		Column{Header: "STATUS"},
+		Column{Header: "RESTARTS"},
+		Column{Header: "AGE"},
+		Column{Header: "IMAGE"},
+		Column{Header: "NODE"},
+		Column{Header: "IP", Wide: true},
+		Column{Header: "CONTROLLED BY", Wide: true},
+		Column{Header: "LABELS", Wide: true},
+	)
+
+	for i := range pods.Items {
+		pod := &pods.Items[i]
 		var containerStatuses []string
 		if pod.Status.Phase == corev1.PodRunning && len(pod.Status.ContainerStatuses) > 0 {
 			for _, cs := range pod.Status.ContainerStatuses {
 				containerStatuses = append(containerStatuses, fmt.Sprintf("%s:%s", cs.Name, strconv.FormatBool(cs.Ready)))
 			}
 		}
-		ready, total := CalculateReadiness(&pod)
+		ready, total := CalculateReadiness(pod)
 		age := HumanReadableDuration(time.Since(pod.ObjectMeta.CreationTimestamp.Time))
-		image := strings.Join(GetContainerImages(&pod), ", ")
+		image := strings.Join(GetContainerImages(pod), ", ")
 		node := pod.Spec.NodeName
 
 		restartCount := 0
@@ -72,64 +140,94 @@ func ShowPodsByFilter(pods *corev1.PodList) {
 			restartCount = int(pod.Status.ContainerStatuses[0].RestartCount)
 		}
 
-		table.Append([]string{
+		owner := "<none>"
+		if ownerKind, ownerName := GetOwnerKindAndName(pod); ownerKind != "" {
+			owner = ownerKind + "/" + ownerName
+		}
+
+		table.Append(pod.Name, pod,
 			pod.Name,
 			fmt.Sprintf("%d/%d", ready, total),
-			string(pod.Status.Phase),
+			PodStatus(pod),
 			strconv.Itoa(restartCount),
 			age,
 			image,
 			node,
-		})
+			pod.Status.PodIP,
+			owner,
+			strings.Join(GetLabels(pod), ","),
+		)
 	}
-	table.Render()
+	return table
 }
 
 func ShowNamespaceByFilter(namespaces *corev1.NamespaceList) {
-	table := tablewriter.NewWriter(os.Stdout)
-	table.Header([]string{
-		"NAME",
-		"STATUS",
-		"AGE",
-	})
-
-	for _, ns := range namespaces.Items {
+	RenderTable(NamespaceTable(namespaces))
+}
+
+func NamespaceTable(namespaces *corev1.NamespaceList) *Table {
+	table := NewTable("namespace",
+		Column{Header: "NAME"},
+		Column{Header: "STATUS"},
+		Column{Header: "AGE"},
+		Column{Header: "LABELS", Wide: true},
+	)
+
+	for i := range namespaces.Items {
+		ns := &namespaces.Items[i]
 		name := ns.ObjectMeta.Name
 		status := ns.Status.Phase
 		age := HumanReadableDuration(time.Since(ns.ObjectMeta.CreationTimestamp.Time))
 
-		table.Append([]string{
+		table.Append(name, ns,
 			name,
 			string(status),
 			age,
-		})
+			formatLabels(ns.Labels),
+		)
 	}
-	table.Render()
+	return table
 }
 
-func ShowDeploymentByFilter(deployments *v1.DeploymentList) {
-	table := tablewriter.NewWriter(os.Stdout)
-	table.Header([]string{
-		"NAME",
-		"READY",
-		"UP-TO-DATE",
-		"AVAILABLE",
-		"AGE",
-	})
-
-	for _, deploy := range deployments.Items {
+func DeploymentTable(deployments *v1.DeploymentList) *Table {
+	table := NewTable("deployment.apps",
+		Column{Header: "NAME"},
+		Column{Header: "READY"},
+		Column{Header: "UP-TO-DATE"},
+		Column{Header: "AVAILABLE"},
+		Column{Header: "AGE"},
+		Column{Header: "CONTAINERS", Wide: true},
+		Column{Header: "IMAGES", Wide: true},
+		Column{Header: "SELECTOR", Wide: true},
+	)
+
+	for i := range deployments.Items {
+		deploy := &deployments.Items[i]
 		name := deploy.Name
 		age := HumanReadableDuration(time.Since(deploy.ObjectMeta.CreationTimestamp.Time))
 
-		table.Append([]string{
+		var containers, images []string
+		for _, container := range deploy.Spec.Template.Spec.Containers {
+			containers = append(containers, container.Name)
+			images = append(images, container.Image)
+		}
+		selector := "<none>"
+		if deploy.Spec.Selector != nil {
+			selector = metav1.FormatLabelSelector(deploy.Spec.Selector)
+		}
+
+		table.Append(name, deploy,
 			name,
 			fmt.Sprintf("%d/%d", deploy.Status.ReadyReplicas, deploy.Status.Replicas),
 			fmt.Sprintf("%d", deploy.Status.UpdatedReplicas),
 			fmt.Sprintf("%d", deploy.Status.AvailableReplicas),
 			age,
-		})
+			strings.Join(containers, ","),
+			strings.Join(images, ","),
+			selector,
+		)
 	}
-	table.Render()
+	return table
 }
 
 func DescribePods(pod *corev1.Pod) {
@@ -178,75 +276,92 @@ func DescribePods(pod *corev1.Pod) {
 }
 
 func DescribePodsDetail(pod *corev1.Pod) {
+	if printed, err := PrintObject("pod", pod.Name, pod); printed || err != nil {
+		if err != nil {
+			fmt.Println("Error printing output:", err)
+		}
+		return
+	}
+	WritePodDetail(os.Stdout, pod)
+}
+
+// WritePodDetail writes the describe text of pod to w.
+func WritePodDetail(w io.Writer, pod *corev1.Pod) {
 	var state string
 
 	// Print detailed information about the pod
-	fmt.Printf("Name:      \t%s\n", pod.ObjectMeta.Name)
-	fmt.Printf("Namespace: \t%s\n", pod.ObjectMeta.Namespace)
-	fmt.Printf("Priority:  \t%d\n", pod.Spec.Priority)
-	fmt.Printf("Node:      \t%s\n", pod.Spec.NodeName)
-	fmt.Printf("Start Time:\t%s\n", pod.Status.StartTime.Time)
+	fmt.Fprintf(w, "Name:      \t%s\n", pod.ObjectMeta.Name)
+	fmt.Fprintf(w, "Namespace: \t%s\n", pod.ObjectMeta.Namespace)
+	fmt.Fprintf(w, "Priority:  \t%d\n", pod.Spec.Priority)
+	fmt.Fprintf(w, "Node:      \t%s\n", pod.Spec.NodeName)
+	if pod.Status.StartTime != nil {
+		fmt.Fprintf(w, "Start Time:\t%s\n", pod.Status.StartTime.Time)
+	} else {
+		fmt.Fprintf(w, "Start Time:\t<none>\n")
+	}
 
 	// Convert labels to YAML
 	labelsYAML, err := yaml.Marshal(pod.ObjectMeta.Labels)
 	if err != nil {
-		fmt.Println("Error marshaling labels to YAML:", err)
+		fmt.Fprintln(w, "Error marshaling labels to YAML:", err)
 	} else {
-		fmt.Printf("Labels: \n")
+		fmt.Fprintf(w, "Labels: \n")
 		yamlLines := strings.Split(string(labelsYAML), "\n")
 		for _, line := range yamlLines {
-			fmt.Printf("\t\t%s\n", line)
+			fmt.Fprintf(w, "\t\t%s\n", line)
 		}
 	}
 
 	labelsAnnotation, err := yaml.Marshal(pod.ObjectMeta.Annotations)
 	if err != nil {
-		fmt.Println("Error marshaling labels to YAML:", err)
+		fmt.Fprintln(w, "Error marshaling labels to YAML:", err)
 	} else {
-		fmt.Printf("Annotations: \n")
+		fmt.Fprintf(w, "Annotations: \n")
 		yamlLines := strings.Split(string(labelsAnnotation), "\n")
 		for _, line := range yamlLines {
-			fmt.Printf("\t\t%s\n", line)
+			fmt.Fprintf(w, "\t\t%s\n", line)
 		}
 	}
 
-	fmt.Printf("Status:      \t%s\n", pod.Status.Phase)
-	fmt.Printf("IP:          \t%s\n", pod.Status.PodIP)
+	fmt.Fprintf(w, "Status:      \t%s\n", pod.Status.Phase)
+	fmt.Fprintf(w, "IP:          \t%s\n", pod.Status.PodIP)
 
-	fmt.Printf("IPs:\n")
+	fmt.Fprintf(w, "IPs:\n")
 	for _, podIP := range pod.Status.PodIPs {
-		fmt.Printf("  IP: \t\t%s\n", podIP.IP)
+		fmt.Fprintf(w, "  IP: \t\t%s\n", podIP.IP)
+	}
+	if len(pod.ObjectMeta.OwnerReferences) > 0 {
+		fmt.Fprintf(w, "Controlled By: \t%s/%s\n", pod.ObjectMeta.OwnerReferences[0].Kind, pod.ObjectMeta.OwnerReferences[0].Name)
 	}
-	fmt.Printf("Controlled By: \t%s/%s\n", pod.ObjectMeta.OwnerReferences[0].Kind, pod.ObjectMeta.OwnerReferences[0].Name)
-	fmt.Println("---------------------------------------------------------------------------")
-	fmt.Println("Containers:")
+	fmt.Fprintln(w, "---------------------------------------------------------------------------")
+	fmt.Fprintln(w, "Containers:")
 	for _, container := range pod.Spec.Containers {
 
 		containerStatus := GetContainerStatus(pod, container.Name)
 		if containerStatus != nil {
-			fmt.Printf("  %s:\n", container.Name)
-			fmt.Printf("    Container ID: \t%s\n", containerStatus.ContainerID)
-			fmt.Printf("    Image:        \t%s\n", container.Image)
-			fmt.Printf("    Image ID:     \t%s\n", containerStatus.ImageID)
+			fmt.Fprintf(w, "  %s:\n", container.Name)
+			fmt.Fprintf(w, "    Container ID: \t%s\n", containerStatus.ContainerID)
+			fmt.Fprintf(w, "    Image:        \t%s\n", container.Image)
+			fmt.Fprintf(w, "    Image ID:     \t%s\n", containerStatus.ImageID)
 
 			if len(pod.Spec.Containers[0].Ports) > 0 {
 				ports := ""
 				for _, p := range pod.Spec.Containers[0].Ports {
 					ports += fmt.Sprintf("%d/%s, ", p.ContainerPort, p.Protocol)
 				}
-				fmt.Printf("    Port(s):\t\t%s\n", ports[:len(ports)-2])
+				fmt.Fprintf(w, "    Port(s):\t\t%s\n", ports[:len(ports)-2])
 			} else {
-				fmt.Printf("    Port(s):\t\t<none>\n")
+				fmt.Fprintf(w, "    Port(s):\t\t<none>\n")
 			}
 
 			if len(pod.Spec.Containers[0].Ports) > 0 {
 				if pod.Spec.Containers[0].Ports[0].HostPort != 0 {
-					fmt.Printf("    Host Port: \t\t%d\n", pod.Spec.Containers[0].Ports[0].HostPort)
+					fmt.Fprintf(w, "    Host Port: \t\t%d\n", pod.Spec.Containers[0].Ports[0].HostPort)
 				} else {
-					fmt.Printf("    Host Port: \t\t<none>\n")
+					fmt.Fprintf(w, "    Host Port: \t\t<none>\n")
 				}
 			} else {
-				fmt.Printf("    Host Port: \t\t<none>\n")
+				fmt.Fprintf(w, "    Host Port: \t\t<none>\n")
 			}
 
 			if pod.Status.ContainerStatuses[0].State.Running != nil {
@@ -256,103 +371,104 @@ func DescribePodsDetail(pod *corev1.Pod) {
 			} else {
 				state = "Waiting"
 			}
-			fmt.Printf("    State: \t\t%s\n", state)
+			fmt.Fprintf(w, "    State: \t\t%s\n", state)
 			if pod.Status.ContainerStatuses[0].State.Running != nil {
-				fmt.Printf("      Started: \t\t%s\n", pod.Status.ContainerStatuses[0].State.Running.StartedAt.Time)
+				fmt.Fprintf(w, "      Started: \t\t%s\n", pod.Status.ContainerStatuses[0].State.Running.StartedAt.Time)
 			}
 
 			if containerStatus.LastTerminationState.Terminated != nil {
-				fmt.Printf("    Last State:\n")
-				fmt.Printf("      Reason:     \t%s\n", containerStatus.LastTerminationState.Terminated.Reason)
-				fmt.Printf("      Exit Code:  \t%d\n", containerStatus.LastTerminationState.Terminated.ExitCode)
-				fmt.Printf("      Started:    \t%s\n", containerStatus.LastTerminationState.Terminated.StartedAt.Time)
-				fmt.Printf("      Finished:   \t%s\n", containerStatus.LastTerminationState.Terminated.FinishedAt.Time)
+				fmt.Fprintf(w, "    Last State:\n")
+				fmt.Fprintf(w, "      Reason:     \t%s\n", containerStatus.LastTerminationState.Terminated.Reason)
+				fmt.Fprintf(w, "      Exit Code:  \t%d\n", containerStatus.LastTerminationState.Terminated.ExitCode)
+				fmt.Fprintf(w, "      Started:    \t%s\n", containerStatus.LastTerminationState.Terminated.StartedAt.Time)
+				fmt.Fprintf(w, "      Finished:   \t%s\n", containerStatus.LastTerminationState.Terminated.FinishedAt.Time)
 			}
 
-			fmt.Printf("    Ready:        \t%t\n", containerStatus.Ready)
-			fmt.Printf("    Restart Count: \t%d\n", containerStatus.RestartCount)
-			fmt.Printf("    Limits:\n")
-			fmt.Printf("      cpu:        %s\n", container.Resources.Limits.Cpu().String())
-			fmt.Printf("      memory:     %s\n", container.Resources.Limits.Memory().String())
-			fmt.Printf("    Requests:\n")
-			fmt.Printf("      cpu:        %s\n", container.Resources.Requests.Cpu().String())
-			fmt.Printf("      memory:     %s\n", container.Resources.Requests.Memory().String())
+			fmt.Fprintf(w, "    Ready:        \t%t\n", containerStatus.Ready)
+			fmt.Fprintf(w, "    Restart Count: \t%d\n", containerStatus.RestartCount)
+			fmt.Fprintf(w, "    Limits:\n")
+			fmt.Fprintf(w, "      cpu:        %s\n", container.Resources.Limits.Cpu().String())
+			fmt.Fprintf(w, "      memory:     %s\n", container.Resources.Limits.Memory().String())
+			fmt.Fprintf(w, "    Requests:\n")
+			fmt.Fprintf(w, "      cpu:        %s\n", container.Resources.Requests.Cpu().String())
+			fmt.Fprintf(w, "      memory:     %s\n", container.Resources.Requests.Memory().String())
 
 			labelsYAML, err := yaml.Marshal(container.Env)
 			if err != nil {
-				fmt.Println("Error marshaling environment variables to YAML:", err)
+				fmt.Fprintln(w, "Error marshaling environment variables to YAML:", err)
 			} else {
-				fmt.Printf("    Environment:\n")
+				fmt.Fprintf(w, "    Environment:\n")
 				env := make([]map[string]interface{}, 0)
 				if err := yaml.Unmarshal(labelsYAML, &env); err != nil {
-					fmt.Println("Error unmarshaling environment variables from YAML:", err)
+					fmt.Fprintln(w, "Error unmarshaling environment variables from YAML:", err)
 				} else {
 					for _, v := range env {
 						name := v["name"].(string)
-						value := v["value"].(string)
-						fmt.Printf("      %s: %s\n", name, value)
+						// Variables set with valueFrom have no value
+						value, _ := v["value"].(string)
+						fmt.Fprintf(w, "      %s: %s\n", name, value)
 					}
 				}
 			}
 
-			fmt.Printf("    Mounts:\n")
+			fmt.Fprintf(w, "    Mounts:\n")
 			for _, mount := range container.VolumeMounts {
-				fmt.Printf("      %s from %s (ro:%t)\n", mount.MountPath, mount.Name, mount.ReadOnly)
+				fmt.Fprintf(w, "      %s from %s (ro:%t)\n", mount.MountPath, mount.Name, mount.ReadOnly)
 			}
 
-			fmt.Println("Conditions:")
-			fmt.Printf("  Type: \t\tStatus\n")
+			fmt.Fprintln(w, "Conditions:")
+			fmt.Fprintf(w, "  Type: \t\tStatus\n")
 			for _, cond := range pod.Status.Conditions {
 				if cond.Type == "Initialized" {
-					fmt.Printf("  %s\t\t%s\n", cond.Type, cond.Status)
+					fmt.Fprintf(w, "  %s\t\t%s\n", cond.Type, cond.Status)
 				}
 				if cond.Type == "Ready" {
-					fmt.Printf("  %s\t\t\t%s\n", cond.Type, cond.Status)
+					fmt.Fprintf(w, "  %s\t\t\t%s\n", cond.Type, cond.Status)
 				}
 				if cond.Type == "ContainersReady" {
-					fmt.Printf("  %s\t%s\n", cond.Type, cond.Status)
+					fmt.Fprintf(w, "  %s\t%s\n", cond.Type, cond.Status)
 				}
 				if cond.Type == "PodScheduled" {
-					fmt.Printf("  %s\t\t%s\n", cond.Type, cond.Status)
+					fmt.Fprintf(w, "  %s\t\t%s\n", cond.Type, cond.Status)
 				}
 			}
 
-			fmt.Println("Volumes:")
+			fmt.Fprintln(w, "Volumes:")
 			for _, volume := range pod.Spec.Volumes {
 				switch {
 				case volume.ConfigMap != nil:
-					fmt.Printf("  %s:\n", volume.Name)
-					fmt.Printf("    Type: ConfigMap\n")
-					fmt.Printf("    Name: %s\n", volume.ConfigMap.Name)
+					fmt.Fprintf(w, "  %s:\n", volume.Name)
+					fmt.Fprintf(w, "    Type: ConfigMap\n")
+					fmt.Fprintf(w, "    Name: %s\n", volume.ConfigMap.Name)
 					if volume.ConfigMap.Optional != nil {
-						fmt.Printf("    Optional: %t\n", *volume.ConfigMap.Optional)
+						fmt.Fprintf(w, "    Optional: %t\n", *volume.ConfigMap.Optional)
 					} else {
-						fmt.Printf("    Optional: false\n")
+						fmt.Fprintf(w, "    Optional: false\n")
 					}
 				case volume.Secret != nil:
-					fmt.Printf("  %s:\n", volume.Name)
-					fmt.Printf("    Type: Secret\n")
-					fmt.Printf("    Name: %s\n", volume.Secret.SecretName)
+					fmt.Fprintf(w, "  %s:\n", volume.Name)
+					fmt.Fprintf(w, "    Type: Secret\n")
+					fmt.Fprintf(w, "    Name: %s\n", volume.Secret.SecretName)
 					if volume.Secret.Optional != nil {
-						fmt.Printf("    Optional: %t\n", *volume.Secret.Optional)
+						fmt.Fprintf(w, "    Optional: %t\n", *volume.Secret.Optional)
 					} else {
-						fmt.Printf("    Optional: false\n")
+						fmt.Fprintf(w, "    Optional: false\n")
 					}
 				default:
-					fmt.Printf("  %s: Unknown volume type\n", volume.Name)
+					fmt.Fprintf(w, "  %s: Unknown volume type\n", volume.Name)
 				}
 			}
 
 			// QoS Class
-			fmt.Printf("QoS Class: \t\t%s\n", pod.Status.QOSClass)
+			fmt.Fprintf(w, "QoS Class: \t\t%s\n", pod.Status.QOSClass)
 
 			// Node Selectors
 			nodeSelectors := "<none>"
 			if len(pod.Spec.NodeSelector) > 0 {
 				nodeSelectors = fmt.Sprintf("%v", pod.Spec.NodeSelector)
 			}
-			fmt.Printf("Node-Selectors: \t%s\n", nodeSelectors)
-			fmt.Printf("\n")
+			fmt.Fprintf(w, "Node-Selectors: \t%s\n", nodeSelectors)
+			fmt.Fprintf(w, "\n")
 
 			// Tolerations
 			tolerations := pod.Spec.Tolerations
@@ -362,20 +478,20 @@ func DescribePodsDetail(pod *corev1.Pod) {
 				tolerationStrings = append(tolerationStrings, fmt.Sprintf("%s:%s op=%s for %ds", toleration.Key, toleration.Operator, toleration.Effect, toleration.TolerationSeconds))
 			}
 			tolerationsString := strings.Join(tolerationStrings, "\n\t\t")
-			fmt.Printf("Tolerations:\t%s\n", strings.ReplaceAll(fmt.Sprintf("%v", tolerationsString), " ", "\t"))
+			fmt.Fprintf(w, "Tolerations:\t%s\n", strings.ReplaceAll(fmt.Sprintf("%v", tolerationsString), " ", "\t"))
 
 			// Events
 			events := "<none>"
-			fmt.Println("Events:")
+			fmt.Fprintln(w, "Events:")
 			if len(pod.Status.Conditions) > 0 {
 				events = ""
 				for _, condition := range pod.Status.Conditions {
-					fmt.Printf("  %-16s %v\n", condition.Type, condition.Status)
-					fmt.Printf("  Last Timestamp:  %v\n", condition.LastTransitionTime)
+					fmt.Fprintf(w, "  %-16s %v\n", condition.Type, condition.Status)
+					fmt.Fprintf(w, "  Last Timestamp:  %v\n", condition.LastTransitionTime)
 				}
 				events = strings.TrimSuffix(events, ", ")
 			}
-			fmt.Printf("\t%s\n", events)
+			fmt.Fprintf(w, "\t%s\n", events)
 		}
 	}
 }
@@ -425,6 +541,13 @@ func ByteCountSI(b int64) string {
 }
 
 func DescribeNode(node *corev1.Node) {
+	if printed, err := PrintObject("node", node.Name, node); printed || err != nil {
+		if err != nil {
+			fmt.Println("Error printing output:", err)
+		}
+		return
+	}
+
 	// Print detailed information about the node
 	fmt.Println("Name:\t", node.ObjectMeta.Name)
 
@@ -454,7 +577,7 @@ func DescribeNode(node *corev1.Node) {
 
 	fmt.Println("Allocatable Resources:")
 	for resourceName, quantity := range node.Status.Allocatable {
-		if resourceName == "memory" || resourceName == "pods" || resourceName == "memory" {
+		if resourceName == "memory" || resourceName == "pods" {
 			fmt.Printf("  %s: \t\t%s\n", resourceName, quantity.String())
 		} else if resourceName == "cpu" {
 			fmt.Printf("  %s: \t\t\t%s\n", resourceName, quantity.String())
@@ -467,7 +590,7 @@ func DescribeNode(node *corev1.Node) {
 
 	fmt.Println("Capacity:")
 	for capacity, quantity := range node.Status.Capacity {
-		if capacity == "memory" || capacity == "pods" || capacity == "memory" {
+		if capacity == "memory" || capacity == "pods" {
 			fmt.Printf("  %s: \t\t%s\n", capacity, quantity.String())
 		} else if capacity == "cpu" {
 			fmt.Printf("  %s: \t\t\t%s\n", capacity, quantity.String())
@@ -510,45 +633,73 @@ func DescribeNode(node *corev1.Node) {
 	fmt.Printf("  Architecture: \t\t%s\n", node.Status.NodeInfo.Architecture)
 }
 
+// NodeResource is a row of the node resources table, also used for its -o
+// json/yaml output.
+type NodeResource struct {
+	Resource    string `json:"resource"`
+	Allocatable string `json:"allocatable"`
+	Capacity    string `json:"capacity"`
+}
+
 func DescribeNodeTable(node *corev1.Node) {
+	if printed, err := PrintObject("node", node.Name, node); printed || err != nil {
+		if err != nil {
+			fmt.Println("Error printing output:", err)
+		}
+		return
+	}
+
 	fmt.Printf("Name:\t%s\n", node.Name)
 
 	labels, _ := json.MarshalIndent(node.Labels, "", "\t")
 	fmt.Printf("Labels:\n%s\n", string(labels))
 
-	addresses := node.Status.Addresses
-	table := tablewriter.NewWriter(os.Stdout)
-	table.Header([]string{"Type", "Address"})
-	for _, addr := range addresses {
-		table.Append([]string{string(addr.Type), addr.Address})
+	addresses := NewTable("address",
+		Column{Header: "Type"},
+		Column{Header: "Address"},
+	)
+	for _, addr := range node.Status.Addresses {
+		addresses.Append(addr.Address, addr, string(addr.Type), addr.Address)
 	}
-	table.Render()
-
-	table = tablewriter.NewWriter(os.Stdout)
-	table.Header([]string{"Resource", "Allocatable", "Capacity"})
-	allocatable := node.Status.Allocatable
-	capacity := node.Status.Capacity
-	for resourceName, quantity := range allocatable {
-		capacityQuantity := capacity[resourceName]
-		table.Append([]string{string(resourceName), quantity.String(), capacityQuantity.String()})
+	RenderTable(addresses)
+
+	resources := NewTable("resource",
+		Column{Header: "Resource"},
+		Column{Header: "Allocatable"},
+		Column{Header: "Capacity"},
+	)
+	var resourceNames []string
+	for resourceName := range node.Status.Allocatable {
+		resourceNames = append(resourceNames, string(resourceName))
+	}
+	sort.Strings(resourceNames)
+	for _, resourceName := range resourceNames {
+		allocatable := node.Status.Allocatable[corev1.ResourceName(resourceName)]
+		capacity := node.Status.Capacity[corev1.ResourceName(resourceName)]
+		row := NodeResource{Resource: resourceName, Allocatable: allocatable.String(), Capacity: capacity.String()}
+		resources.Append(resourceName, row, row.Resource, row.Allocatable, row.Capacity)
 	}
-	table.Render()
+	RenderTable(resources)
 
-	table = tablewriter.NewWriter(os.Stdout)
-	table.Header([]string{"Condition", "Status"})
+	conditions := NewTable("condition",
+		Column{Header: "Condition"},
+		Column{Header: "Status"},
+	)
 	for _, condition := range node.Status.Conditions {
-		table.Append([]string{string(condition.Type), BoolToString(condition.Status)})
+		conditions.Append(string(condition.Type), condition, string(condition.Type), BoolToString(condition.Status))
 	}
-	table.Render()
+	RenderTable(conditions)
 
 	fmt.Printf("Daemon Endpoint Port:\t%d\n", node.Status.DaemonEndpoints.KubeletEndpoint.Port)
 
-	table = tablewriter.NewWriter(os.Stdout)
-	table.Header([]string{"Name", "Size"})
+	images := NewTable("image",
+		Column{Header: "Name"},
+		Column{Header: "Size"},
+	)
 	for _, image := range node.Status.Images {
-		table.Append([]string{image.Names[0], ByteCountSI(image.SizeBytes)})
+		images.Append(image.Names[0], image, image.Names[0], ByteCountSI(image.SizeBytes))
 	}
-	table.Render()
+	RenderTable(images)
 
 	fmt.Printf("Machine ID:\t\t%s\n", node.Status.NodeInfo.MachineID)
 	fmt.Printf("System UUID:\t\t%s\n", node.Status.NodeInfo.SystemUUID)
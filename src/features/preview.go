@@ -0,0 +1,112 @@
+package features
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+var dryRun bool
+
+// RenderRedacted serializes a copy of the config with tokens, passwords,
+// auth provider settings, exec plugin environment and embedded certificate
+// data replaced, so it is safe to print or share.
+func RenderRedacted(config *clientcmdapi.Config) ([]byte, error) {
+	redacted := config.DeepCopy()
+	clientcmdapi.ShortenConfig(redacted)
+	if err := clientcmdapi.RedactSecrets(redacted); err != nil {
+		return nil, err
+	}
+	// RedactSecrets only knows the fields of AuthInfo itself, id-token,
+	// refresh-token and client-secret live in the provider config
+	for _, authInfo := range redacted.AuthInfos {
+		if authInfo.AuthProvider != nil {
+			for key := range authInfo.AuthProvider.Config {
+				authInfo.AuthProvider.Config[key] = redactedValue
+			}
+		}
+		if authInfo.Exec != nil {
+			for i := range authInfo.Exec.Env {
+				authInfo.Exec.Env[i].Value = redactedValue
+			}
+		}
+	}
+	return clientcmd.Write(*redacted)
+}
+
+// redactedValue is what clientcmdapi.RedactSecrets puts in place of secrets.
+const redactedValue = "REDACTED"
+
+func DiffKubeconfig(path string, config *clientcmdapi.Config) (string, error) {
+	var current []byte
+	if _, err := os.Stat(path); err == nil {
+		loaded, err := clientcmd.LoadFromFile(path)
+		if err != nil {
+			return "", err
+		}
+		current, err = RenderRedacted(loaded)
+		if err != nil {
+			return "", err
+		}
+	} else if !os.IsNotExist(err) {
+		return "", err
+	}
+
+	updated, err := RenderRedacted(config)
+	if err != nil {
+		return "", err
+	}
+
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(current)),
+		B:        difflib.SplitLines(string(updated)),
+		FromFile: path,
+		ToFile:   path + " (dry-run)",
+		Context:  3,
+	})
+}
+
+func PreviewKubeconfig(path string, config *clientcmdapi.Config) error {
+	diff, err := DiffKubeconfig(path, config)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("\n> Dry run, no changes written to: %s\n", path)
+	if diff == "" {
+		fmt.Println("> No changes")
+		return nil
+	}
+	fmt.Print(diff)
+	return nil
+}
+
+// PreviewFileChange shows what applying change to the single file at path
+// would look like, for edits that clientcmd.ModifyConfig routes to one file.
+func PreviewFileChange(path string, change func(config *clientcmdapi.Config)) error {
+	config := clientcmdapi.NewConfig()
+	if _, err := os.Stat(path); err == nil {
+		config, err = clientcmd.LoadFromFile(path)
+		if err != nil {
+			return err
+		}
+	}
+	change(config)
+	return PreviewKubeconfig(path, config)
+}
+
+// WriteKubeconfig is the single write path for kubeconfig files. In dry-run
+// mode it only prints the diff against the file on disk, otherwise the file
+// is backed up before it is overwritten.
+func WriteKubeconfig(path string, config *clientcmdapi.Config) error {
+	if dryRun {
+		return PreviewKubeconfig(path, config)
+	}
+	if _, err := BackupKubeconfig(path); err != nil {
+		return fmt.Errorf("can't back up %s, nothing written: %v", path, err)
+	}
+	return clientcmd.WriteToFile(*config, path)
+}
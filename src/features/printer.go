@@ -0,0 +1,470 @@
+package features
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/olekukonko/tablewriter/pkg/twwidth"
+	"github.com/olekukonko/tablewriter/tw"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/util/jsonpath"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	OutputTable         = ""
+	OutputWide          = "wide"
+	OutputJSON          = "json"
+	OutputYAML          = "yaml"
+	OutputName          = "name"
+	OutputCustomColumns = "custom-columns"
+)
+
+var outputFormat string
+
+type Column struct {
+	Header string
+	// Wide columns are only shown with -o wide
+	Wide bool
+}
+
+type Row struct {
+	Name      string
+	Namespace string
+	Cells     []string
+	Object    interface{}
+	// Event is the watch event type (ADDED, MODIFIED, DELETED) of the row
+	Event string
+}
+
+// Table is what every renderer produces. The printer turns it into a table,
+// structured JSON/YAML of the row objects, names or custom columns depending
+// on the -o flag.
+type Table struct {
+	Kind    string
+	Columns []Column
+	Rows    []Row
+	// AllNamespaces adds a NAMESPACE column in front
+	AllNamespaces bool
+}
+
+func NewTable(kind string, columns ...Column) *Table {
+	return &Table{Kind: kind, Columns: columns}
+}
+
+func (t *Table) Append(name string, object interface{}, cells ...string) {
+	row := Row{Name: name, Cells: cells, Object: object}
+	if accessor, err := meta.Accessor(object); err == nil {
+		row.Namespace = accessor.GetNamespace()
+	}
+	t.Rows = append(t.Rows, row)
+}
+
+func ValidateOutputFormat() error {
+	switch {
+	case outputFormat == OutputTable, outputFormat == OutputWide, outputFormat == OutputJSON,
+		outputFormat == OutputYAML, outputFormat == OutputName:
+		return nil
+	case strings.HasPrefix(outputFormat, OutputCustomColumns+"="):
+		_, err := parseCustomColumns(strings.TrimPrefix(outputFormat, OutputCustomColumns+"="))
+		return err
+	}
+	return fmt.Errorf("unknown output format: %s (available: json, yaml, wide, name, custom-columns=<NAME>:<JSONPATH>,...)", outputFormat)
+}
+
+// IsStructuredOutput reports whether the output is meant for other programs,
+// in which case headings and other decorations are left out.
+func IsStructuredOutput() bool {
+	return outputFormat != OutputTable && outputFormat != OutputWide
+}
+
+func PrintHeading(format string, args ...interface{}) {
+	if !IsStructuredOutput() {
+		fmt.Printf(format, args...)
+	}
+}
+
+// PrintTable prints a table that is complete in one go.
+func PrintTable(table *Table) error {
+	printer := NewTablePrinter()
+	if err := printer.Print(table); err != nil {
+		printer.Close()
+		return err
+	}
+	return printer.Close()
+}
+
+// TablePrinter prints tables that arrive in parts, pages of a list or the
+// namespaces of a cluster, as one table. Rows are written as soon as they
+// arrive. Column widths are fixed by the first part, longer cells in later
+// parts wrap. JSON and YAML are written item by item into a single List.
+type TablePrinter struct {
+	writer    *tablewriter.Table
+	columns   []customColumn
+	items     int
+	minWidths map[string]int
+	notes     []string
+	events    bool
+}
+
+func NewTablePrinter() *TablePrinter {
+	return &TablePrinter{minWidths: make(map[string]int)}
+}
+
+// Reserve widens a column before the first part is printed, for values that
+// are known to come later.
+func (p *TablePrinter) Reserve(header string, width int) {
+	header = strings.ToUpper(header)
+	p.minWidths[header] = max(p.minWidths[header], width)
+}
+
+// ShowEvents switches to watch output: an EVENT column in tables, and for
+// JSON and YAML one watch event document per row instead of a List, as the
+// output has no end.
+func (p *TablePrinter) ShowEvents() {
+	p.events = true
+	p.Reserve("EVENT", len(watch.Modified))
+}
+
+// Notef adds a message for stderr, printed after the table is closed so it
+// doesn't end up between the rows.
+func (p *TablePrinter) Notef(format string, args ...interface{}) {
+	p.notes = append(p.notes, fmt.Sprintf(format, args...))
+}
+
+func (p *TablePrinter) Print(table *Table) error {
+	switch {
+	case p.events && (outputFormat == OutputJSON || outputFormat == OutputYAML):
+		for _, row := range table.Rows {
+			if err := p.printEvent(row); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case outputFormat == OutputJSON, outputFormat == OutputYAML:
+		for _, row := range table.Rows {
+			if err := p.printItem(withKind(row.Object)); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case outputFormat == OutputName:
+		for _, row := range table.Rows {
+			fmt.Printf("%s/%s\n", table.Kind, row.Name)
+		}
+		return nil
+	}
+
+	headers, rows, err := p.cells(table)
+	if err != nil {
+		return err
+	}
+	if p.writer == nil {
+		if err := p.start(headers, rows); err != nil {
+			return err
+		}
+	}
+	for _, row := range rows {
+		if err := p.writer.Append(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *TablePrinter) Close() error {
+	defer func() {
+		for _, note := range p.notes {
+			fmt.Fprintln(os.Stderr, note)
+		}
+	}()
+
+	switch {
+	case p.events && (outputFormat == OutputJSON || outputFormat == OutputYAML):
+		return nil
+	case outputFormat == OutputJSON && p.items == 0:
+		return printStructured(map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "List",
+			"items":      []interface{}{},
+		})
+	case outputFormat == OutputJSON:
+		fmt.Print("\n    ],\n    \"kind\": \"List\"\n}\n")
+	case outputFormat == OutputYAML && p.items == 0:
+		fmt.Print("apiVersion: v1\nitems: []\nkind: List\n")
+	case outputFormat == OutputYAML:
+		fmt.Print("kind: List\n")
+	case p.writer != nil:
+		return p.writer.Close()
+	}
+	return nil
+}
+
+// printItem writes one item of the List, the same way printStructured
+// would have indented it inside the whole list.
+func (p *TablePrinter) printItem(object interface{}) error {
+	data, err := json.MarshalIndent(object, "        ", "    ")
+	if err != nil {
+		return err
+	}
+
+	if outputFormat == OutputYAML {
+		data, err = yaml.JSONToYAML(data)
+		if err != nil {
+			return err
+		}
+		if p.items == 0 {
+			fmt.Print("apiVersion: v1\nitems:\n")
+		}
+		lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+		for i, line := range lines {
+			if i == 0 {
+				fmt.Println("- " + line)
+			} else {
+				fmt.Println("  " + line)
+			}
+		}
+		p.items++
+		return nil
+	}
+
+	if p.items == 0 {
+		fmt.Print("{\n    \"apiVersion\": \"v1\",\n    \"items\": [\n")
+	} else {
+		fmt.Print(",\n")
+	}
+	fmt.Print("        " + string(data))
+	p.items++
+	return nil
+}
+
+// printEvent writes a row as a watch event, the same shape kubectl prints
+// with --output-watch-events.
+func (p *TablePrinter) printEvent(row Row) error {
+	event := struct {
+		Type   string      `json:"type"`
+		Object interface{} `json:"object"`
+	}{Type: row.Event, Object: withKind(row.Object)}
+
+	if outputFormat == OutputYAML && p.items > 0 {
+		fmt.Println("---")
+	}
+	p.items++
+	return printStructured(event)
+}
+
+// cells picks the visible columns of table, or evaluates the custom columns
+// against the row objects.
+func (p *TablePrinter) cells(table *Table) ([]string, [][]string, error) {
+	if strings.HasPrefix(outputFormat, OutputCustomColumns+"=") {
+		if p.columns == nil {
+			columns, err := parseCustomColumns(strings.TrimPrefix(outputFormat, OutputCustomColumns+"="))
+			if err != nil {
+				return nil, nil, err
+			}
+			p.columns = columns
+		}
+		return customColumnCells(p.columns, table)
+	}
+
+	wide := outputFormat == OutputWide
+	var headers []string
+	if p.events {
+		headers = append(headers, "EVENT")
+	}
+	if table.AllNamespaces {
+		headers = append(headers, "NAMESPACE")
+	}
+	for _, column := range table.Columns {
+		if wide || !column.Wide {
+			headers = append(headers, column.Header)
+		}
+	}
+
+	rows := make([][]string, 0, len(table.Rows))
+	for _, row := range table.Rows {
+		var cells []string
+		if p.events {
+			cells = append(cells, row.Event)
+		}
+		if table.AllNamespaces {
+			cells = append(cells, row.Namespace)
+		}
+		for i, column := range table.Columns {
+			if wide || !column.Wide {
+				cell := ""
+				if i < len(row.Cells) {
+					cell = row.Cells[i]
+				}
+				cells = append(cells, cell)
+			}
+		}
+		rows = append(rows, cells)
+	}
+	return headers, rows, nil
+}
+
+// start opens the streaming table. Streaming needs the column widths up
+// front, they are taken from the headers and the first rows.
+func (p *TablePrinter) start(headers []string, rows [][]string) error {
+	widths := tw.NewMapper[int, int]()
+	for i, header := range headers {
+		headers[i] = strings.ToUpper(header)
+		width := max(cellWidth(headers[i]), p.minWidths[headers[i]])
+		for _, row := range rows {
+			if i < len(row) {
+				width = max(width, cellWidth(row[i]))
+			}
+		}
+		// Cell padding is part of the width
+		widths.Set(i, width+2)
+	}
+
+	p.writer = tablewriter.NewTable(os.Stdout,
+		tablewriter.WithStreaming(tw.StreamConfig{Enable: true}),
+		tablewriter.WithColumnWidths(widths),
+		tablewriter.WithHeaderAutoFormat(tw.Off),
+		tablewriter.WithRowAutoWrap(tw.WrapBreak),
+	)
+	if err := p.writer.Start(); err != nil {
+		return err
+	}
+	p.writer.Header(headers)
+	return nil
+}
+
+func cellWidth(cell string) int {
+	width := 0
+	for _, line := range strings.Split(cell, "\n") {
+		width = max(width, twwidth.Width(line))
+	}
+	return width
+}
+
+// RenderTable prints the table for renderers that have no caller to report
+// errors to.
+func RenderTable(table *Table) {
+	if err := PrintTable(table); err != nil {
+		fmt.Println("Error printing output:", err)
+	}
+}
+
+// PrintObject prints a single object for the structured formats and reports
+// whether it did, so describe style commands can fall back to their text.
+func PrintObject(kind string, name string, object interface{}) (bool, error) {
+	switch {
+	case outputFormat == OutputJSON, outputFormat == OutputYAML:
+		return true, printStructured(withKind(object))
+	case outputFormat == OutputName:
+		fmt.Printf("%s/%s\n", kind, name)
+		return true, nil
+	case strings.HasPrefix(outputFormat, OutputCustomColumns+"="):
+		table := NewTable(kind)
+		table.Append(name, object)
+		return true, PrintTable(table)
+	}
+	return false, nil
+}
+
+func printStructured(object interface{}) error {
+	data, err := json.MarshalIndent(object, "", "    ")
+	if err != nil {
+		return err
+	}
+	if outputFormat == OutputYAML {
+		data, err = yaml.JSONToYAML(data)
+		if err != nil {
+			return err
+		}
+		fmt.Print(string(data))
+		return nil
+	}
+	fmt.Println(string(data))
+	return nil
+}
+
+// Objects returned by typed clients carry no apiVersion/kind, fill them in
+// from the scheme so structured output can be fed back to kubectl.
+func withKind(object interface{}) interface{} {
+	obj, ok := object.(runtime.Object)
+	if !ok {
+		return object
+	}
+	if !obj.GetObjectKind().GroupVersionKind().Empty() {
+		return object
+	}
+	gvks, _, err := scheme.Scheme.ObjectKinds(obj)
+	if err != nil || len(gvks) == 0 {
+		return object
+	}
+	obj = obj.DeepCopyObject()
+	obj.GetObjectKind().SetGroupVersionKind(gvks[0])
+	return obj
+}
+
+type customColumn struct {
+	Header string
+	Path   *jsonpath.JSONPath
+}
+
+func parseCustomColumns(spec string) ([]customColumn, error) {
+	var columns []customColumn
+	for _, part := range strings.Split(spec, ",") {
+		header, path, ok := strings.Cut(part, ":")
+		if !ok || header == "" || path == "" {
+			return nil, fmt.Errorf("invalid custom column %q, expected <NAME>:<JSONPATH>", part)
+		}
+		if !strings.HasPrefix(path, "{") {
+			path = "{" + path + "}"
+		}
+		parser := jsonpath.New(header).AllowMissingKeys(true)
+		if err := parser.Parse(path); err != nil {
+			return nil, fmt.Errorf("invalid custom column %q: %v", part, err)
+		}
+		columns = append(columns, customColumn{Header: header, Path: parser})
+	}
+	return columns, nil
+}
+
+func customColumnCells(columns []customColumn, table *Table) ([]string, [][]string, error) {
+	headers := make([]string, len(columns))
+	for i, column := range columns {
+		headers[i] = column.Header
+	}
+
+	rows := make([][]string, 0, len(table.Rows))
+	for _, row := range table.Rows {
+		// Go through JSON so the paths match the API field names
+		data, err := json.Marshal(row.Object)
+		if err != nil {
+			return nil, nil, err
+		}
+		var object interface{}
+		if err := json.Unmarshal(data, &object); err != nil {
+			return nil, nil, err
+		}
+
+		cells := make([]string, len(columns))
+		for i, column := range columns {
+			buf := new(bytes.Buffer)
+			if err := column.Path.Execute(buf, object); err != nil {
+				return nil, nil, err
+			}
+			cells[i] = buf.String()
+			if cells[i] == "" {
+				cells[i] = "<none>"
+			}
+		}
+		rows = append(rows, cells)
+	}
+	return headers, rows, nil
+}
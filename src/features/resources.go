@@ -0,0 +1,179 @@
+package features
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+)
+
+// ListResources lists one resource type in a namespace, or in all of them
+// when namespace is empty, and hands emit a table per page. options carries
+// the label and field selectors, the page size and whether to keep watching
+// for changes, filter the client-side pod filters.
+func ListResources(ctx context.Context, clientset kubernetes.Interface, resource string, namespace string, options metav1.ListOptions, filter PodFilter, emit func(*Table) error) error {
+	switch resource {
+	case "pods", "po":
+		return listAndWatch(ctx, clientset.CoreV1().Pods(namespace), options, func(pods *corev1.PodList) *Table {
+			return PodTable(FilterPods(pods, filter))
+		}, emit)
+
+	case "services", "svc":
+		return listAndWatch(ctx, clientset.CoreV1().Services(namespace), options, ServiceTable, emit)
+
+	case "endpoints", "ep", "endpointslices", "endpointslice":
+		// A service's slices can end up on different pages, its row needs
+		// all of them
+		client := clientset.DiscoveryV1().EndpointSlices(namespace)
+		all := &discoveryv1.EndpointSliceList{}
+		err := listPages(ctx, client.List, options, func(slices *discoveryv1.EndpointSliceList) error {
+			all.Items = append(all.Items, slices.Items...)
+			all.ResourceVersion = slices.ResourceVersion
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if err := emit(watchEvent(EndpointSliceTable(all), options, watch.Added)); err != nil {
+			return err
+		}
+		// Changes come per slice, each row shows the slice that changed
+		return watchTables(ctx, client, all.ResourceVersion, options, EndpointSliceTable, emit)
+
+	case "deployments", "deployment", "deploy":
+		return listAndWatch(ctx, clientset.AppsV1().Deployments(namespace), options, DeploymentTable, emit)
+
+	case "statefulsets", "statefulset", "sts":
+		return listAndWatch(ctx, clientset.AppsV1().StatefulSets(namespace), options, StatefulSetTable, emit)
+
+	case "daemonsets", "daemonset", "ds":
+		return listAndWatch(ctx, clientset.AppsV1().DaemonSets(namespace), options, DaemonSetTable, emit)
+
+	case "replicasets", "replicaset", "rs":
+		return listAndWatch(ctx, clientset.AppsV1().ReplicaSets(namespace), options, ReplicaSetTable, emit)
+
+	case "jobs", "job":
+		return listAndWatch(ctx, clientset.BatchV1().Jobs(namespace), options, JobTable, emit)
+
+	case "cronjobs", "cronjob", "cj":
+		return listAndWatch(ctx, clientset.BatchV1().CronJobs(namespace), options, CronJobTable, emit)
+
+	case "ingresses", "ingress", "ing":
+		return listAndWatch(ctx, clientset.NetworkingV1().Ingresses(namespace), options, IngressTable, emit)
+
+	case "networkpolicies", "networkpolicy", "netpol":
+		return listAndWatch(ctx, clientset.NetworkingV1().NetworkPolicies(namespace), options, NetworkPolicyTable, emit)
+
+	case "httproutes", "httproute":
+		resource, err := HTTPRouteResource(clientset)
+		if err != nil {
+			return err
+		}
+		client, err := GetDynamicClient(ActiveKubeconfig())
+		if err != nil {
+			return err
+		}
+		return listAndWatch(ctx, client.Resource(resource).Namespace(namespace), options, HTTPRouteTable, emit)
+
+	case "configmaps", "configmap", "cm":
+		return listAndWatch(ctx, clientset.CoreV1().ConfigMaps(namespace), options, ConfigMapTable, emit)
+
+	case "secrets", "secret":
+		return listAndWatch(ctx, clientset.CoreV1().Secrets(namespace), options, SecretTable, emit)
+
+	case "persistentvolumeclaims", "persistentvolumeclaim", "pvc":
+		return listAndWatch(ctx, clientset.CoreV1().PersistentVolumeClaims(namespace), options, PersistentVolumeClaimTable, emit)
+
+	case "persistentvolumes", "persistentvolume", "pv":
+		return listAndWatch(ctx, clientset.CoreV1().PersistentVolumes(), options, PersistentVolumeTable, emit)
+
+	case "storageclasses", "storageclass", "sc":
+		return listAndWatch(ctx, clientset.StorageV1().StorageClasses(), options, StorageClassTable, emit)
+
+	default:
+		return fmt.Errorf("unknown resource type: %s", resource)
+	}
+}
+
+// listPages calls list until the server has no more pages and hands every
+// page to emit, so only one page is held at a time. options.Limit sets the
+// page size, 0 gets everything at once.
+func listPages[T metav1.ListInterface](ctx context.Context, list func(context.Context, metav1.ListOptions) (T, error), options metav1.ListOptions, emit func(T) error) error {
+	// Watching starts after the list, see listAndWatch
+	options.Watch = false
+	for {
+		page, err := list(ctx, options)
+		if err != nil {
+			return err
+		}
+		if err := emit(page); err != nil {
+			return err
+		}
+		options.Continue = page.GetContinue()
+		if options.Continue == "" {
+			return nil
+		}
+	}
+}
+
+// IsTypedResource reports whether ListResources has a table for resource,
+// anything else is looked up through discovery.
+func IsTypedResource(resource string) bool {
+	switch resource {
+	case "pods", "po",
+		"services", "svc",
+		"endpoints", "ep", "endpointslices", "endpointslice",
+		"deployments", "deployment", "deploy",
+		"statefulsets", "statefulset", "sts",
+		"daemonsets", "daemonset", "ds",
+		"replicasets", "replicaset", "rs",
+		"jobs", "job",
+		"cronjobs", "cronjob", "cj",
+		"ingresses", "ingress", "ing",
+		"networkpolicies", "networkpolicy", "netpol",
+		"httproutes", "httproute",
+		"configmaps", "configmap", "cm",
+		"secrets", "secret",
+		"persistentvolumeclaims", "persistentvolumeclaim", "pvc",
+		"persistentvolumes", "persistentvolume", "pv",
+		"storageclasses", "storageclass", "sc":
+		return true
+	}
+	return false
+}
+
+// IsClusterScoped reports whether resource is listed once for the cluster
+// instead of per namespace.
+func IsClusterScoped(resource string) bool {
+	switch resource {
+	case "persistentvolumes", "persistentvolume", "pv",
+		"storageclasses", "storageclass", "sc":
+		return true
+	}
+	return false
+}
+
+// ListNamespaceTable lists the given namespaces, or every namespace
+// matching options when none are given.
+func ListNamespaceTable(ctx context.Context, clientset kubernetes.Interface, names []string, options metav1.ListOptions, emit func(*Table) error) error {
+	if len(names) == 0 {
+		return listAndWatch(ctx, clientset.CoreV1().Namespaces(), options, NamespaceTable, emit)
+	}
+	if options.Watch {
+		return fmt.Errorf("--watch can't be combined with -n for namespaces")
+	}
+
+	namespaces := &corev1.NamespaceList{}
+	for _, name := range names {
+		ns, err := clientset.CoreV1().Namespaces().Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		namespaces.Items = append(namespaces.Items, *ns)
+	}
+	return emit(NamespaceTable(namespaces))
+}
@@ -0,0 +1,329 @@
+package features
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+)
+
+// openAPISchema is the part of an OpenAPI v3 schema needed to explain a
+// field. References are resolved against the components of the same document.
+type openAPISchema struct {
+	Ref                  string                    `json:"$ref,omitempty"`
+	AllOf                []*openAPISchema          `json:"allOf,omitempty"`
+	Type                 string                    `json:"type,omitempty"`
+	Format               string                    `json:"format,omitempty"`
+	Description          string                    `json:"description,omitempty"`
+	Required             []string                  `json:"required,omitempty"`
+	Enum                 []interface{}             `json:"enum,omitempty"`
+	Properties           map[string]*openAPISchema `json:"properties,omitempty"`
+	Items                *openAPISchema            `json:"items,omitempty"`
+	AdditionalProperties json.RawMessage           `json:"additionalProperties,omitempty"`
+	PreserveUnknown      bool                      `json:"x-kubernetes-preserve-unknown-fields,omitempty"`
+	IntOrString          bool                      `json:"x-kubernetes-int-or-string,omitempty"`
+	GroupVersionKinds    []struct {
+		Group   string `json:"group"`
+		Version string `json:"version"`
+		Kind    string `json:"kind"`
+	} `json:"x-kubernetes-group-version-kind,omitempty"`
+}
+
+type openAPIDocument struct {
+	Components struct {
+		Schemas map[string]*openAPISchema `json:"schemas"`
+	} `json:"components"`
+}
+
+// FieldSchema is a field found by ExplainField, ready to print.
+type FieldSchema struct {
+	Kind        string            `json:"kind"`
+	Version     string            `json:"version"`
+	Field       string            `json:"field,omitempty"`
+	Type        string            `json:"type"`
+	Description string            `json:"description,omitempty"`
+	Fields      []FieldSchemaItem `json:"fields,omitempty"`
+}
+
+type FieldSchemaItem struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Required    bool   `json:"required,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// ExplainField looks up query, a resource optionally followed by a field
+// path (deploy.spec.template, certificates.cert-manager.io.spec.secretName),
+// in the OpenAPI v3 document the cluster serves for the resource's group.
+func ExplainField(client discovery.DiscoveryInterface, query string) (*FieldSchema, error) {
+	resource, fields, err := resolveSchemaQuery(client, query)
+	if err != nil {
+		return nil, err
+	}
+
+	document, err := openAPIDocumentFor(client, resource.GroupVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	gvk := resource.GroupVersion.WithKind(resource.Kind)
+	current := document.findKind(gvk)
+	if current == nil {
+		return nil, fmt.Errorf("no schema published for %s", gvk.String())
+	}
+
+	for i, field := range fields {
+		current = document.resolve(current)
+		next := document.child(current, field)
+		if next == nil {
+			return nil, fmt.Errorf("field %q does not exist in %s", field, strings.Join(append([]string{resource.Kind}, fields[:i]...), "."))
+		}
+		current = next
+	}
+
+	explained := &FieldSchema{
+		Kind:        resource.Kind,
+		Version:     resource.GroupVersion.String(),
+		Field:       strings.Join(fields, "."),
+		Type:        document.typeName(current),
+		Description: document.description(current),
+	}
+
+	// Fields of the object itself, or of the element type for lists and maps
+	object := document.resolve(current)
+	for object != nil && object.Items != nil {
+		object = document.resolve(object.Items)
+	}
+	if object != nil {
+		if values := object.additionalProperties(); values != nil && len(object.Properties) == 0 {
+			object = document.resolve(values)
+		}
+	}
+	if object != nil {
+		required := make(map[string]bool)
+		for _, name := range object.Required {
+			required[name] = true
+		}
+		for _, name := range sortedKeys(object.Properties) {
+			property := object.Properties[name]
+			explained.Fields = append(explained.Fields, FieldSchemaItem{
+				Name:        name,
+				Type:        document.typeName(property),
+				Required:    required[name],
+				Description: document.description(property),
+			})
+		}
+	}
+	return explained, nil
+}
+
+// resolveSchemaQuery splits query into a resource and a field path. Group
+// names contain dots too, so the longest prefix naming a resource wins.
+func resolveSchemaQuery(client discovery.DiscoveryInterface, query string) (APIResource, []string, error) {
+	if strings.Contains(query, "/") {
+		prefix := query[:strings.LastIndex(query, "/")+1]
+		kind, fieldPath, _ := strings.Cut(query[len(prefix):], ".")
+		resource, err := ResolveResource(client, prefix+kind)
+		if err != nil {
+			return APIResource{}, nil, err
+		}
+		return resource, splitFieldPath(fieldPath), nil
+	}
+
+	resources, err := ServerResources(client)
+	if err != nil {
+		return APIResource{}, nil, err
+	}
+	parts := strings.Split(query, ".")
+	for i := len(parts); i > 0; i-- {
+		resource, err := findResource(resources, strings.Join(parts[:i], "."))
+		if err == nil {
+			return resource, parts[i:], nil
+		}
+		if i == 1 {
+			return APIResource{}, nil, err
+		}
+	}
+	return APIResource{}, nil, fmt.Errorf("unknown resource type: %s", query)
+}
+
+func splitFieldPath(fieldPath string) []string {
+	if fieldPath == "" {
+		return nil
+	}
+	return strings.Split(fieldPath, ".")
+}
+
+func openAPIDocumentFor(client discovery.DiscoveryInterface, groupVersion schema.GroupVersion) (*openAPIDocument, error) {
+	paths, err := client.OpenAPIV3().Paths()
+	if err != nil {
+		return nil, err
+	}
+
+	key := "apis/" + groupVersion.String()
+	if groupVersion.Group == "" {
+		key = "api/" + groupVersion.Version
+	}
+	path, ok := paths[key]
+	if !ok {
+		return nil, fmt.Errorf("the server publishes no OpenAPI v3 document for %s", groupVersion.String())
+	}
+
+	data, err := path.Schema(runtime.ContentTypeJSON)
+	if err != nil {
+		return nil, err
+	}
+	document := &openAPIDocument{}
+	if err := json.Unmarshal(data, document); err != nil {
+		return nil, err
+	}
+	return document, nil
+}
+
+func (d *openAPIDocument) findKind(gvk schema.GroupVersionKind) *openAPISchema {
+	for _, name := range sortedKeys(d.Components.Schemas) {
+		candidate := d.Components.Schemas[name]
+		for _, candidateGVK := range candidate.GroupVersionKinds {
+			if candidateGVK.Group == gvk.Group && candidateGVK.Version == gvk.Version && candidateGVK.Kind == gvk.Kind {
+				return candidate
+			}
+		}
+	}
+	return nil
+}
+
+// resolve follows $ref and the single element allOf wrappers the API server
+// uses to attach a description to a reference.
+func (d *openAPIDocument) resolve(s *openAPISchema) *openAPISchema {
+	for depth := 0; s != nil && depth < 10; depth++ {
+		switch {
+		case s.Ref != "":
+			s = d.Components.Schemas[strings.TrimPrefix(s.Ref, "#/components/schemas/")]
+		case len(s.AllOf) == 1 && s.Type == "" && len(s.Properties) == 0:
+			s = s.AllOf[0]
+		default:
+			return s
+		}
+	}
+	return s
+}
+
+func (d *openAPIDocument) child(s *openAPISchema, field string) *openAPISchema {
+	for s != nil {
+		if property, ok := s.Properties[field]; ok {
+			return property
+		}
+		switch {
+		case s.Items != nil:
+			s = d.resolve(s.Items)
+		case s.additionalProperties() != nil:
+			s = d.resolve(s.additionalProperties())
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+func (d *openAPIDocument) description(s *openAPISchema) string {
+	// A reference wrapped in allOf carries the field's own description
+	if s.Description != "" {
+		return s.Description
+	}
+	if resolved := d.resolve(s); resolved != nil {
+		return resolved.Description
+	}
+	return ""
+}
+
+func (d *openAPIDocument) typeName(s *openAPISchema) string {
+	if s == nil {
+		return "<unknown>"
+	}
+	if s.Ref == "" && len(s.AllOf) == 1 {
+		return d.typeName(s.AllOf[0])
+	}
+	if s.Ref != "" {
+		name := s.Ref[strings.LastIndex(s.Ref, ".")+1:]
+		if resolved := d.resolve(s); resolved != nil && resolved.Type != "" && resolved.Type != "object" {
+			// Types like Quantity and Time are strings on the wire
+			return resolved.Type
+		}
+		return name
+	}
+	switch {
+	case s.IntOrString:
+		return "IntOrString"
+	case s.Type == "array" && s.Items != nil:
+		return "[]" + d.typeName(s.Items)
+	case s.Type == "object" && s.additionalProperties() != nil:
+		return "map[string]" + d.typeName(s.additionalProperties())
+	case s.Type == "object", s.Type == "":
+		return "Object"
+	}
+	return s.Type
+}
+
+func (s *openAPISchema) additionalProperties() *openAPISchema {
+	if len(s.AdditionalProperties) == 0 {
+		return nil
+	}
+	var values openAPISchema
+	if err := json.Unmarshal(s.AdditionalProperties, &values); err != nil {
+		// additionalProperties: true
+		return nil
+	}
+	return &values
+}
+
+func ShowFieldSchema(explained *FieldSchema) error {
+	if printed, err := PrintObject("schema", explained.Kind, explained); printed || err != nil {
+		return err
+	}
+
+	fmt.Printf("KIND:     %s\n", explained.Kind)
+	fmt.Printf("VERSION:  %s\n", explained.Version)
+	if explained.Field != "" {
+		fmt.Printf("FIELD:    %s <%s>\n", explained.Field, explained.Type)
+	}
+	fmt.Printf("\nDESCRIPTION:\n%s\n", indentText(orNone(explained.Description), "    "))
+
+	if len(explained.Fields) == 0 {
+		return nil
+	}
+	fmt.Printf("\nFIELDS:\n")
+	for _, field := range explained.Fields {
+		required := ""
+		if field.Required {
+			required = " -required-"
+		}
+		fmt.Printf("  %s\t<%s>%s\n", field.Name, field.Type, required)
+		fmt.Printf("%s\n\n", indentText(firstParagraph(orNone(field.Description)), "    "))
+	}
+	return nil
+}
+
+func orNone(text string) string {
+	if text == "" {
+		return "<empty>"
+	}
+	return text
+}
+
+func firstParagraph(text string) string {
+	paragraph, _, _ := strings.Cut(text, "\n\n")
+	return paragraph
+}
+
+func indentText(text string, indent string) string {
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = indent + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
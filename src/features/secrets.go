@@ -0,0 +1,131 @@
+package features
+
+import (
+	"fmt"
+	"sort"
+	"time"
+	"unicode/utf8"
+
+	survey "github.com/AlecAivazis/survey/v2"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// revealSecrets is set by get --reveal, secret values are never printed
+// without it.
+var revealSecrets bool
+
+const lastAppliedAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+func ConfigMapTable(configMaps *corev1.ConfigMapList) *Table {
+	table := NewTable("configmap",
+		Column{Header: "NAME"},
+		Column{Header: "DATA"},
+		Column{Header: "AGE"},
+	)
+
+	for i := range configMaps.Items {
+		cm := &configMaps.Items[i]
+		table.Append(cm.Name, cm,
+			cm.Name,
+			fmt.Sprintf("%d", len(cm.Data)+len(cm.BinaryData)),
+			HumanReadableDuration(time.Since(cm.CreationTimestamp.Time)),
+		)
+	}
+	return table
+}
+
+func SecretTable(secrets *corev1.SecretList) *Table {
+	if revealSecrets {
+		return secretValuesTable(secrets)
+	}
+
+	table := NewTable("secret",
+		Column{Header: "NAME"},
+		Column{Header: "TYPE"},
+		Column{Header: "DATA"},
+		Column{Header: "AGE"},
+	)
+
+	for i := range secrets.Items {
+		secret := &secrets.Items[i]
+		table.Append(secret.Name, RedactSecret(secret),
+			secret.Name,
+			string(secret.Type),
+			fmt.Sprintf("%d", len(secret.Data)),
+			HumanReadableDuration(time.Since(secret.CreationTimestamp.Time)),
+		)
+	}
+	return table
+}
+
+// secretValuesTable has one row per key with its decoded value. Structured
+// output gets the secret as the API returned it.
+func secretValuesTable(secrets *corev1.SecretList) *Table {
+	table := NewTable("secret",
+		Column{Header: "NAME"},
+		Column{Header: "KEY"},
+		Column{Header: "VALUE"},
+	)
+
+	for i := range secrets.Items {
+		secret := &secrets.Items[i]
+		if IsStructuredOutput() {
+			table.Append(secret.Name, secret)
+			continue
+		}
+		if len(secret.Data) == 0 {
+			table.Append(secret.Name, secret, secret.Name, "<none>", "")
+			continue
+		}
+		keys := make([]string, 0, len(secret.Data))
+		for key := range secret.Data {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			table.Append(secret.Name, secret, secret.Name, key, secretValue(secret.Data[key]))
+		}
+	}
+
+	return table
+}
+
+func secretValue(value []byte) string {
+	if !utf8.Valid(value) {
+		return fmt.Sprintf("<binary, %s>", ByteCountSI(int64(len(value))))
+	}
+	return string(value)
+}
+
+// RedactSecret returns a copy of secret with its values removed, keeping the
+// keys. The last-applied annotation is dropped as it holds the values too.
+func RedactSecret(secret *corev1.Secret) *corev1.Secret {
+	redacted := secret.DeepCopy()
+	for key := range redacted.Data {
+		redacted.Data[key] = nil
+	}
+	for key := range redacted.StringData {
+		redacted.StringData[key] = ""
+	}
+	delete(redacted.Annotations, lastAppliedAnnotation)
+	return redacted
+}
+
+// ConfirmReveal asks before secret values are printed to the terminal.
+func ConfirmReveal() error {
+	if !IsInteractive() {
+		return fmt.Errorf("--reveal needs a terminal to confirm printing secret values")
+	}
+	confirmed := false
+	prompt := &survey.Confirm{
+		Message: "Print decoded secret values to the terminal?",
+		Default: false,
+	}
+	if err := survey.AskOne(prompt, &confirmed); err != nil {
+		return err
+	}
+	if !confirmed {
+		return fmt.Errorf("secret values not revealed")
+	}
+	return nil
+}
@@ -0,0 +1,193 @@
+package features
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"k8s.io/client-go/util/homedir"
+)
+
+const SessionDirName = "k8c-sessions"
+
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+func SafeFileName(name string) string {
+	return strings.Trim(unsafeFileChars.ReplaceAllString(name, "_"), "_")
+}
+
+// SessionFile is the file "k8c env" keeps the session of contextName in.
+// Every call for a context reuses it, so the directory holds at most one file
+// per context. The hash keeps apart contexts SafeFileName maps to the same
+// name.
+func SessionFile(contextName string) string {
+	sum := sha256.Sum256([]byte(contextName))
+	name := fmt.Sprintf("%s-%x.yaml", SafeFileName(contextName), sum[:4])
+	return filepath.Join(homedir.HomeDir(), ".kube", SessionDirName, name)
+}
+
+// ExtractContext returns a self-contained copy of config holding only the
+// named context and the cluster and user it references. Relative certificate
+// paths are resolved against the file they came from so the copy still works
+// when written somewhere else.
+func ExtractContext(config *clientcmdapi.Config, contextName string) (*clientcmdapi.Config, error) {
+	if _, ok := config.Contexts[contextName]; !ok {
+		return nil, fmt.Errorf("context not found: %s", contextName)
+	}
+
+	extracted := config.DeepCopy()
+	extracted.CurrentContext = contextName
+	if err := clientcmd.ResolveLocalPaths(extracted); err != nil {
+		return nil, err
+	}
+	if err := clientcmdapi.MinifyConfig(extracted); err != nil {
+		return nil, err
+	}
+	return extracted, nil
+}
+
+func resolveSessionContext(query string, config *clientcmdapi.Config) (string, error) {
+	match, candidates := ResolveContext(query, SortedContextNames(config))
+	if match != "" {
+		return match, nil
+	}
+	if len(candidates) == 0 {
+		return "", fmt.Errorf("no context matches %q", query)
+	}
+	return "", fmt.Errorf("context %q is ambiguous, candidates:\n  %s", query, strings.Join(candidates, "\n  "))
+}
+
+func StartShell(kubeconfigPath string, query string) error {
+	config, err := LoadKubeconfig(kubeconfigPath)
+	if err != nil {
+		return err
+	}
+	contextName, err := resolveSessionContext(query, config)
+	if err != nil {
+		return err
+	}
+	session, err := ExtractContext(config, contextName)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp("", "k8c-"+SafeFileName(contextName)+"-*.yaml")
+	if err != nil {
+		return err
+	}
+	sessionFile := file.Name()
+	file.Close()
+	defer os.Remove(sessionFile)
+
+	if err := clientcmd.WriteToFile(*session, sessionFile); err != nil {
+		return err
+	}
+
+	shell := os.Getenv("SHELL")
+	if runtime.GOOS == "windows" {
+		shell = os.Getenv("COMSPEC")
+	}
+	if shell == "" {
+		shell = "/bin/sh"
+	}
+
+	cmd := exec.Command(shell)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(), "KUBECONFIG="+sessionFile, "K8C_CONTEXT="+contextName)
+
+	// Interrupts belong to the subshell, k8c only waits to clean up afterwards
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt)
+	defer signal.Stop(signals)
+
+	fmt.Printf("> Starting %s with context: %s (exit to return)\n", shell, contextName)
+	err = cmd.Run()
+	fmt.Printf("> Left context session: %s\n", contextName)
+
+	// The exit status of the last command in the subshell is not our error
+	if _, ok := err.(*exec.ExitError); ok {
+		return nil
+	}
+	return err
+}
+
+func PrintSessionEnv(kubeconfigPath string, query string, shell string) error {
+	switch shell {
+	case "sh", "bash", "zsh", "", "fish", "powershell":
+	default:
+		return fmt.Errorf("unknown shell: %s (available: sh, fish, powershell)", shell)
+	}
+
+	config, err := LoadKubeconfig(kubeconfigPath)
+	if err != nil {
+		return err
+	}
+	contextName, err := resolveSessionContext(query, config)
+	if err != nil {
+		return err
+	}
+	session, err := ExtractContext(config, contextName)
+	if err != nil {
+		return err
+	}
+
+	sessionFile := SessionFile(contextName)
+	if err := writeSessionFile(session, sessionFile); err != nil {
+		return err
+	}
+
+	switch shell {
+	case "fish":
+		fmt.Printf("set -gx KUBECONFIG %s;\n", quoteFor(shell, sessionFile))
+		fmt.Printf("set -gx K8C_CONTEXT %s;\n", quoteFor(shell, contextName))
+	case "powershell":
+		fmt.Printf("$Env:KUBECONFIG = %s\n", quoteFor(shell, sessionFile))
+		fmt.Printf("$Env:K8C_CONTEXT = %s\n", quoteFor(shell, contextName))
+	default:
+		fmt.Printf("export KUBECONFIG=%s\n", quoteFor(shell, sessionFile))
+		fmt.Printf("export K8C_CONTEXT=%s\n", quoteFor(shell, contextName))
+	}
+	return nil
+}
+
+// writeSessionFile replaces path in one step, shells already using the
+// session never read a half written file.
+func writeSessionFile(session *clientcmdapi.Config, path string) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	file, err := os.CreateTemp(dir, ".tmp-*.yaml")
+	if err != nil {
+		return err
+	}
+	tmp := file.Name()
+	file.Close()
+	defer os.Remove(tmp)
+
+	if err := clientcmd.WriteToFile(*session, tmp); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func quoteFor(shell string, value string) string {
+	switch shell {
+	case "fish":
+		return "'" + strings.NewReplacer(`\`, `\\`, "'", `\'`).Replace(value) + "'"
+	case "powershell":
+		return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+	default:
+		return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+	}
+}
@@ -0,0 +1,161 @@
+package features
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"k8s.io/client-go/util/homedir"
+)
+
+const (
+	StateFileName  = "k8c-state.json"
+	MaxHistory     = 50
+	DefaultHistory = 10
+)
+
+type SwitchRecord struct {
+	Context    string    `json:"context"`
+	Previous   string    `json:"previous"`
+	Kubeconfig string    `json:"kubeconfig"`
+	Time       time.Time `json:"time"`
+}
+
+type NamespaceRecord struct {
+	Context   string    `json:"context"`
+	Namespace string    `json:"namespace"`
+	Previous  string    `json:"previous"`
+	Time      time.Time `json:"time"`
+}
+
+type State struct {
+	History          []SwitchRecord    `json:"history"`
+	NamespaceHistory []NamespaceRecord `json:"namespaceHistory,omitempty"`
+}
+
+func StateFile() string {
+	return filepath.Join(homedir.HomeDir(), ".kube", StateFileName)
+}
+
+func LoadState() (*State, error) {
+	state := &State{}
+	data, err := os.ReadFile(StateFile())
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("can't read k8c state file %s: %v", StateFile(), err)
+	}
+	return state, nil
+}
+
+func (s *State) Save() error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(StateFile()), 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(StateFile(), data, 0600)
+}
+
+func RecordSwitch(kubeconfigPath string, previous string, current string) error {
+	state, err := LoadState()
+	if err != nil {
+		return err
+	}
+
+	absPath, err := filepath.Abs(kubeconfigPath)
+	if err != nil {
+		absPath = kubeconfigPath
+	}
+
+	state.History = append(state.History, SwitchRecord{
+		Context:    current,
+		Previous:   previous,
+		Kubeconfig: absPath,
+		Time:       time.Now(),
+	})
+	if len(state.History) > MaxHistory {
+		state.History = state.History[len(state.History)-MaxHistory:]
+	}
+	return state.Save()
+}
+
+func PreviousContext() (string, error) {
+	state, err := LoadState()
+	if err != nil {
+		return "", err
+	}
+	if len(state.History) == 0 || state.History[len(state.History)-1].Previous == "" {
+		return "", fmt.Errorf("no previous context recorded yet")
+	}
+	return state.History[len(state.History)-1].Previous, nil
+}
+
+func RecordNamespace(context string, previous string, namespace string) error {
+	state, err := LoadState()
+	if err != nil {
+		return err
+	}
+
+	state.NamespaceHistory = append(state.NamespaceHistory, NamespaceRecord{
+		Context:   context,
+		Namespace: namespace,
+		Previous:  previous,
+		Time:      time.Now(),
+	})
+	if len(state.NamespaceHistory) > MaxHistory {
+		state.NamespaceHistory = state.NamespaceHistory[len(state.NamespaceHistory)-MaxHistory:]
+	}
+	return state.Save()
+}
+
+func PreviousNamespace(context string) (string, error) {
+	state, err := LoadState()
+	if err != nil {
+		return "", err
+	}
+	for i := len(state.NamespaceHistory) - 1; i >= 0; i-- {
+		if state.NamespaceHistory[i].Context == context {
+			return state.NamespaceHistory[i].Previous, nil
+		}
+	}
+	return "", fmt.Errorf("no previous namespace recorded for context: %s", context)
+}
+
+func ShowHistory(limit int) error {
+	state, err := LoadState()
+	if err != nil {
+		return err
+	}
+	if len(state.History) == 0 && !IsStructuredOutput() {
+		fmt.Println("No context switches recorded yet")
+		return nil
+	}
+
+	table := NewTable("switch",
+		Column{Header: "#"},
+		Column{Header: "CONTEXT"},
+		Column{Header: "PREVIOUS"},
+		Column{Header: "KUBECONFIG"},
+		Column{Header: "TIME"},
+	)
+	for i := len(state.History) - 1; i >= 0 && len(state.History)-i <= limit; i-- {
+		record := state.History[i]
+		table.Append(record.Context, record,
+			fmt.Sprintf("%d", len(state.History)-i),
+			record.Context,
+			record.Previous,
+			record.Kubeconfig,
+			record.Time.Format("2006-01-02 15:04:05"),
+		)
+	}
+	return PrintTable(table)
+}
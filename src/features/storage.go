@@ -0,0 +1,159 @@
+package features
+
+import (
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+)
+
+const defaultStorageClassAnnotation = "storageclass.kubernetes.io/is-default-class"
+
+func PersistentVolumeClaimTable(claims *corev1.PersistentVolumeClaimList) *Table {
+	table := NewTable("persistentvolumeclaim",
+		Column{Header: "NAME"},
+		Column{Header: "STATUS"},
+		Column{Header: "VOLUME"},
+		Column{Header: "CAPACITY"},
+		Column{Header: "ACCESS MODES"},
+		Column{Header: "STORAGECLASS"},
+		Column{Header: "AGE"},
+		Column{Header: "VOLUMEMODE", Wide: true},
+	)
+
+	for i := range claims.Items {
+		pvc := &claims.Items[i]
+		capacity := ""
+		if storage, ok := pvc.Status.Capacity[corev1.ResourceStorage]; ok {
+			capacity = storage.String()
+		}
+		storageClass := ""
+		if pvc.Spec.StorageClassName != nil {
+			storageClass = *pvc.Spec.StorageClassName
+		}
+		volumeMode := "<unset>"
+		if pvc.Spec.VolumeMode != nil {
+			volumeMode = string(*pvc.Spec.VolumeMode)
+		}
+
+		table.Append(pvc.Name, pvc,
+			pvc.Name,
+			string(pvc.Status.Phase),
+			pvc.Spec.VolumeName,
+			capacity,
+			formatAccessModes(pvc.Status.AccessModes),
+			storageClass,
+			HumanReadableDuration(time.Since(pvc.CreationTimestamp.Time)),
+			volumeMode,
+		)
+	}
+	return table
+}
+
+func PersistentVolumeTable(volumes *corev1.PersistentVolumeList) *Table {
+	table := NewTable("persistentvolume",
+		Column{Header: "NAME"},
+		Column{Header: "CAPACITY"},
+		Column{Header: "ACCESS MODES"},
+		Column{Header: "RECLAIM POLICY"},
+		Column{Header: "STATUS"},
+		Column{Header: "CLAIM"},
+		Column{Header: "STORAGECLASS"},
+		Column{Header: "REASON"},
+		Column{Header: "AGE"},
+		Column{Header: "VOLUMEMODE", Wide: true},
+	)
+
+	for i := range volumes.Items {
+		pv := &volumes.Items[i]
+		capacity := ""
+		if storage, ok := pv.Spec.Capacity[corev1.ResourceStorage]; ok {
+			capacity = storage.String()
+		}
+		claim := ""
+		if pv.Spec.ClaimRef != nil {
+			claim = pv.Spec.ClaimRef.Namespace + "/" + pv.Spec.ClaimRef.Name
+		}
+		volumeMode := "<unset>"
+		if pv.Spec.VolumeMode != nil {
+			volumeMode = string(*pv.Spec.VolumeMode)
+		}
+
+		table.Append(pv.Name, pv,
+			pv.Name,
+			capacity,
+			formatAccessModes(pv.Spec.AccessModes),
+			string(pv.Spec.PersistentVolumeReclaimPolicy),
+			string(pv.Status.Phase),
+			claim,
+			pv.Spec.StorageClassName,
+			pv.Status.Reason,
+			HumanReadableDuration(time.Since(pv.CreationTimestamp.Time)),
+			volumeMode,
+		)
+	}
+	return table
+}
+
+func StorageClassTable(storageClasses *storagev1.StorageClassList) *Table {
+	table := NewTable("storageclass.storage.k8s.io",
+		Column{Header: "NAME"},
+		Column{Header: "PROVISIONER"},
+		Column{Header: "RECLAIMPOLICY"},
+		Column{Header: "VOLUMEBINDINGMODE"},
+		Column{Header: "ALLOWVOLUMEEXPANSION"},
+		Column{Header: "AGE"},
+	)
+
+	for i := range storageClasses.Items {
+		sc := &storageClasses.Items[i]
+		name := sc.Name
+		if sc.Annotations[defaultStorageClassAnnotation] == "true" {
+			name += " (default)"
+		}
+		reclaimPolicy := string(corev1.PersistentVolumeReclaimDelete)
+		if sc.ReclaimPolicy != nil {
+			reclaimPolicy = string(*sc.ReclaimPolicy)
+		}
+		bindingMode := string(storagev1.VolumeBindingImmediate)
+		if sc.VolumeBindingMode != nil {
+			bindingMode = string(*sc.VolumeBindingMode)
+		}
+		expansion := "false"
+		if sc.AllowVolumeExpansion != nil && *sc.AllowVolumeExpansion {
+			expansion = "true"
+		}
+
+		table.Append(sc.Name, sc,
+			name,
+			sc.Provisioner,
+			reclaimPolicy,
+			bindingMode,
+			expansion,
+			HumanReadableDuration(time.Since(sc.CreationTimestamp.Time)),
+		)
+	}
+	return table
+}
+
+// formatAccessModes abbreviates access modes the way kubectl does (RWO, ROX,
+// RWX, RWOP).
+func formatAccessModes(modes []corev1.PersistentVolumeAccessMode) string {
+	var short []string
+	for _, mode := range modes {
+		switch mode {
+		case corev1.ReadWriteOnce:
+			short = append(short, "RWO")
+		case corev1.ReadOnlyMany:
+			short = append(short, "ROX")
+		case corev1.ReadWriteMany:
+			short = append(short, "RWX")
+		case corev1.ReadWriteOncePod:
+			short = append(short, "RWOP")
+		default:
+			short = append(short, string(mode))
+		}
+	}
+	return strings.Join(short, ",")
+}
@@ -0,0 +1,144 @@
+package features
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"golang.org/x/term"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+type ContextMatch struct {
+	Name  string
+	Score int
+}
+
+func IsInteractive() bool {
+	return term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+func SortedContextNames(config *clientcmdapi.Config) []string {
+	return sortedKeys(config.Contexts)
+}
+
+// ResolveContext finds the context meant by query: an exact name first, then
+// a unique prefix, then the single best fuzzy match. When no single context
+// wins, the remaining candidates are returned best first.
+func ResolveContext(query string, names []string) (string, []string) {
+	for _, name := range names {
+		if name == query {
+			return name, nil
+		}
+	}
+
+	var prefixed []string
+	for _, name := range names {
+		if strings.HasPrefix(name, query) {
+			prefixed = append(prefixed, name)
+		}
+	}
+	if len(prefixed) == 1 {
+		return prefixed[0], nil
+	}
+	if len(prefixed) > 1 {
+		return "", prefixed
+	}
+
+	var matches []ContextMatch
+	for _, name := range names {
+		if score, ok := FuzzyScore(query, name); ok {
+			matches = append(matches, ContextMatch{Name: name, Score: score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Score > matches[j].Score
+	})
+
+	if len(matches) == 1 || (len(matches) > 1 && matches[0].Score > matches[1].Score) {
+		return matches[0].Name, nil
+	}
+
+	candidates := make([]string, len(matches))
+	for i, match := range matches {
+		candidates[i] = match.Name
+	}
+	return "", candidates
+}
+
+// FuzzyScore reports whether every character of query appears in target in
+// order, scoring consecutive runs and matches at word boundaries higher.
+func FuzzyScore(query string, target string) (int, bool) {
+	q := []rune(strings.ToLower(query))
+	t := []rune(strings.ToLower(target))
+	if len(q) == 0 {
+		return 0, true
+	}
+
+	score, ti, prev := 0, 0, -2
+	for _, qc := range q {
+		found := false
+		for ; ti < len(t); ti++ {
+			if t[ti] != qc {
+				continue
+			}
+			score++
+			if ti == prev+1 {
+				score += 5
+			}
+			if ti == 0 || strings.ContainsRune("-_/.:@", t[ti-1]) {
+				score += 3
+			}
+			prev = ti
+			ti++
+			found = true
+			break
+		}
+		if !found {
+			return 0, false
+		}
+	}
+
+	if strings.Contains(string(t), string(q)) {
+		score += 10
+	}
+	// Prefer shorter names when everything else is equal
+	score -= (len(t) - len(q)) / 4
+
+	return score, true
+}
+
+func SwitchContext(query string, config *clientcmdapi.Config) error {
+	contextNames := SortedContextNames(config)
+
+	if query == "" {
+		if !IsInteractive() {
+			return fmt.Errorf("no context given and no terminal to prompt, available contexts:\n  %s", strings.Join(contextNames, "\n  "))
+		}
+		return SelectedConfig(contextNames, config)
+	}
+
+	if query == "-" {
+		previous, err := PreviousContext()
+		if err != nil {
+			return err
+		}
+		if _, ok := config.Contexts[previous]; !ok {
+			return fmt.Errorf("previous context no longer exists: %s", previous)
+		}
+		return ApplyContext(previous, config)
+	}
+
+	match, candidates := ResolveContext(query, contextNames)
+	if match != "" {
+		return ApplyContext(match, config)
+	}
+	if len(candidates) == 0 {
+		return fmt.Errorf("no context matches %q, available contexts:\n  %s", query, strings.Join(contextNames, "\n  "))
+	}
+	if !IsInteractive() {
+		return fmt.Errorf("context %q is ambiguous, candidates:\n  %s", query, strings.Join(candidates, "\n  "))
+	}
+	return SelectedConfig(candidates, config)
+}
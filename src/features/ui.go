@@ -0,0 +1,801 @@
+package features
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/transport/spdy"
+	"k8s.io/klog/v2"
+)
+
+// UIRedrawInterval limits how often informer events redraw the screen, busy
+// namespaces send many of them.
+const UIRedrawInterval = 250 * time.Millisecond
+
+// UILogTail is how many earlier lines the log view starts with.
+const UILogTail = 200
+
+// UILogLines bounds the lines the log view keeps while following.
+const UILogLines = 10000
+
+const uiHelp = " <tab> pane  <1-6> resource  <enter> open  d describe  l logs  p port-forward  P stop port-forward  <esc> back  q quit"
+
+// uiKind is a resource type the dashboard can show, picked with the number
+// keys in this order.
+type uiKind struct {
+	Name     string
+	Informer func(factory informers.SharedInformerFactory) cache.SharedIndexInformer
+	Table    func(objects []runtime.Object) *Table
+}
+
+var uiKinds = []uiKind{
+	{"pods", func(f informers.SharedInformerFactory) cache.SharedIndexInformer {
+		return f.Core().V1().Pods().Informer()
+	}, informerTable(PodTable)},
+	{"deployments", func(f informers.SharedInformerFactory) cache.SharedIndexInformer {
+		return f.Apps().V1().Deployments().Informer()
+	}, informerTable(DeploymentTable)},
+	{"services", func(f informers.SharedInformerFactory) cache.SharedIndexInformer {
+		return f.Core().V1().Services().Informer()
+	}, informerTable(ServiceTable)},
+	{"statefulsets", func(f informers.SharedInformerFactory) cache.SharedIndexInformer {
+		return f.Apps().V1().StatefulSets().Informer()
+	}, informerTable(StatefulSetTable)},
+	{"daemonsets", func(f informers.SharedInformerFactory) cache.SharedIndexInformer {
+		return f.Apps().V1().DaemonSets().Informer()
+	}, informerTable(DaemonSetTable)},
+	{"jobs", func(f informers.SharedInformerFactory) cache.SharedIndexInformer {
+		return f.Batch().V1().Jobs().Informer()
+	}, informerTable(JobTable)},
+}
+
+// uiPods is the index of pods in uiKinds.
+const uiPods = 0
+
+// informerTable adapts a table builder to the objects of an informer store.
+func informerTable[T listObject](table func(T) *Table) func([]runtime.Object) *Table {
+	return func(objects []runtime.Object) *Table {
+		list := newList[T]()
+		// The store only holds items of the list's type, this can't fail
+		_ = meta.SetList(list, objects)
+		return table(list)
+	}
+}
+
+// uiDrill narrows the pods to the ones a workload selects.
+type uiDrill struct {
+	kind      int
+	name      string
+	namespace string
+	selector  labels.Selector
+}
+
+func (drill *uiDrill) matches(object runtime.Object) bool {
+	accessor, err := meta.Accessor(object)
+	if err != nil {
+		return false
+	}
+	return accessor.GetNamespace() == drill.namespace && drill.selector.Matches(labels.Set(accessor.GetLabels()))
+}
+
+// dashboard is the state of "k8c ui". Everything but the informer handlers
+// runs on the tview event loop.
+type dashboard struct {
+	app        *tview.Application
+	pages      *tview.Pages
+	header     *tview.TextView
+	contexts   *tview.List
+	namespaces *tview.List
+	resources  *tview.Table
+	detail     *tview.TextView
+	status     *tview.TextView
+
+	kubeconfig     string
+	config         *clientcmdapi.Config
+	context        string
+	namespace      string
+	kind           int
+	drill          *uiDrill
+	contextNames   []string
+	namespaceNames []string
+
+	clientset  kubernetes.Interface
+	restConfig *rest.Config
+	nsInformer cache.SharedIndexInformer
+	informer   cache.SharedIndexInformer
+	table      *Table
+
+	// contextStop stops the namespace informer, resourceStop the resource one
+	contextStop  chan struct{}
+	resourceStop chan struct{}
+	forwardStop  chan struct{}
+	stopLogs     context.CancelFunc
+	dirty        chan struct{}
+
+	// done is closed when the dashboard closes, pending counts the updates
+	// background goroutines have queued and that still have to run
+	mu      sync.Mutex
+	done    chan struct{}
+	pending sync.WaitGroup
+}
+
+// RunDashboard opens the full-screen dashboard on the contexts of
+// kubeconfigPath until the user quits.
+func RunDashboard(kubeconfigPath string) error {
+	config, err := LoadKubeconfig(kubeconfigPath)
+	if err != nil {
+		return err
+	}
+	if len(config.Contexts) == 0 {
+		return fmt.Errorf("no contexts in %s", kubeconfigPath)
+	}
+	contextName := config.CurrentContext
+	if _, ok := config.Contexts[contextName]; !ok {
+		contextName = SortedContextNames(config)[0]
+	}
+
+	// client-go logs failed lists and watches to stderr, which would tear the
+	// screen. They show up in the status line instead.
+	klog.LogToStderr(false)
+	klog.SetOutput(io.Discard)
+
+	d := newDashboard(kubeconfigPath, config)
+	defer d.close()
+	// The contexts are listed even when the first one does not connect, so
+	// another one can be picked
+	d.refreshContexts()
+	if err := d.connect(contextName); err != nil {
+		d.refreshHeader()
+		d.setError(err)
+		d.app.SetFocus(d.contexts)
+	}
+	go d.redrawLoop()
+	return d.app.Run()
+}
+
+func newDashboard(kubeconfigPath string, config *clientcmdapi.Config) *dashboard {
+	d := &dashboard{
+		app:        tview.NewApplication(),
+		kubeconfig: kubeconfigPath,
+		config:     config,
+		dirty:      make(chan struct{}, 1),
+		done:       make(chan struct{}),
+	}
+
+	d.header = tview.NewTextView()
+	d.status = tview.NewTextView()
+	help := tview.NewTextView().SetText(uiHelp).SetTextColor(tcell.ColorGray)
+
+	d.contexts = tview.NewList().ShowSecondaryText(false)
+	d.contexts.SetBorder(true).SetTitle(" Contexts ")
+	d.contexts.SetSelectedFunc(func(i int, _ string, _ string, _ rune) {
+		d.switchContext(d.contextNames[i])
+	})
+
+	d.namespaces = tview.NewList().ShowSecondaryText(false)
+	d.namespaces.SetBorder(true).SetTitle(" Namespaces ")
+	d.namespaces.SetSelectedFunc(func(i int, _ string, _ string, _ rune) {
+		d.selectNamespace(d.namespaceNames[i])
+	})
+
+	d.resources = tview.NewTable().SetSelectable(true, false).SetFixed(1, 0)
+	d.resources.SetBorder(true)
+	d.resources.SetSelectedFunc(func(int, int) {
+		d.open()
+	})
+
+	d.detail = tview.NewTextView().SetScrollable(true).SetMaxLines(UILogLines)
+	d.detail.SetChangedFunc(func() {
+		d.queueUpdateDraw(func() {})
+	})
+	d.detail.SetBorder(true)
+
+	sidebar := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(d.contexts, 0, 1, false).
+		AddItem(d.namespaces, 0, 2, false)
+	body := tview.NewFlex().
+		AddItem(sidebar, 32, 0, false).
+		AddItem(d.resources, 0, 1, true)
+	main := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(d.header, 1, 0, false).
+		AddItem(body, 0, 1, true).
+		AddItem(d.status, 1, 0, false).
+		AddItem(help, 1, 0, false)
+
+	d.pages = tview.NewPages().
+		AddPage("main", main, true, true).
+		AddPage("detail", d.detail, true, false)
+	d.app.SetRoot(d.pages, true).SetFocus(d.resources).SetInputCapture(d.handleKey)
+	return d
+}
+
+func (d *dashboard) handleKey(event *tcell.EventKey) *tcell.EventKey {
+	if event.Key() == tcell.KeyCtrlC {
+		d.quit()
+		return nil
+	}
+	if page, _ := d.pages.GetFrontPage(); page == "detail" {
+		if event.Key() == tcell.KeyEscape || (event.Key() == tcell.KeyRune && event.Rune() == 'q') {
+			d.closeDetail()
+			return nil
+		}
+		return event
+	}
+
+	switch event.Key() {
+	case tcell.KeyTab:
+		d.cycleFocus()
+		return nil
+	case tcell.KeyEscape:
+		d.back()
+		return nil
+	case tcell.KeyRune:
+	default:
+		return event
+	}
+
+	switch r := event.Rune(); {
+	case r == 'q':
+		d.quit()
+	case r >= '1' && r < '1'+rune(len(uiKinds)):
+		d.kind = int(r - '1')
+		d.drill = nil
+		d.watchResources()
+		d.app.SetFocus(d.resources)
+	case r == 'd':
+		d.describe()
+	case r == 'l':
+		d.logs()
+	case r == 'p':
+		d.forward()
+	case r == 'P':
+		d.stopForward()
+	default:
+		return event
+	}
+	return nil
+}
+
+func (d *dashboard) cycleFocus() {
+	switch d.app.GetFocus() {
+	case d.contexts:
+		d.app.SetFocus(d.namespaces)
+	case d.namespaces:
+		d.app.SetFocus(d.resources)
+	default:
+		d.app.SetFocus(d.contexts)
+	}
+}
+
+// connect points the dashboard at contextName, starting from the namespace
+// the context defaults to.
+func (d *dashboard) connect(contextName string) error {
+	restConfig, err := clientcmd.NewNonInteractiveClientConfig(*d.config, contextName, &clientcmd.ConfigOverrides{}, nil).ClientConfig()
+	if err != nil {
+		return err
+	}
+	clientset, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return err
+	}
+
+	d.context = contextName
+	d.restConfig = restConfig
+	d.clientset = clientset
+	d.namespace = metav1.NamespaceAll
+	if context, ok := d.config.Contexts[contextName]; ok {
+		d.namespace = context.Namespace
+	}
+	d.drill = nil
+
+	if d.contextStop != nil {
+		close(d.contextStop)
+	}
+	d.contextStop = make(chan struct{})
+	factory := informers.NewSharedInformerFactory(clientset, 0)
+	d.nsInformer = factory.Core().V1().Namespaces().Informer()
+	d.watch(d.nsInformer, "namespaces", d.contextStop)
+	factory.Start(d.contextStop)
+
+	d.refreshContexts()
+	d.refreshNamespaces()
+	d.watchResources()
+	return nil
+}
+
+// watchResources starts an informer for the resource and namespace being
+// shown and stops the previous one.
+func (d *dashboard) watchResources() {
+	d.refreshHeader()
+	// Nothing to watch until a context connects
+	if d.clientset == nil {
+		return
+	}
+	if d.resourceStop != nil {
+		close(d.resourceStop)
+	}
+	d.resourceStop = make(chan struct{})
+	factory := informers.NewSharedInformerFactoryWithOptions(d.clientset, 0, informers.WithNamespace(d.namespace))
+	d.informer = uiKinds[d.kind].Informer(factory)
+	d.watch(d.informer, uiKinds[d.kind].Name, d.resourceStop)
+	factory.Start(d.resourceStop)
+
+	d.refreshResources()
+}
+
+// watch redraws on every change informer sees and reports its list and
+// watch errors until stop is closed.
+func (d *dashboard) watch(informer cache.SharedIndexInformer, resource string, stop chan struct{}) {
+	changed := func() {
+		select {
+		case d.dirty <- struct{}{}:
+		default:
+		}
+	}
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(interface{}) { changed() },
+		UpdateFunc: func(interface{}, interface{}) { changed() },
+		DeleteFunc: func(interface{}) { changed() },
+	})
+	_ = informer.SetWatchErrorHandler(func(_ *cache.Reflector, err error) {
+		d.queueUpdateDraw(func() {
+			select {
+			case <-stop:
+				// From a context or namespace we already left
+			default:
+				d.setError(fmt.Errorf("%s: %v", resource, err))
+			}
+		})
+	})
+}
+
+func (d *dashboard) redrawLoop() {
+	for {
+		select {
+		case <-d.done:
+			return
+		case <-d.dirty:
+		}
+		d.queueUpdateDraw(func() {
+			d.refreshNamespaces()
+			d.refreshResources()
+		})
+		time.Sleep(UIRedrawInterval)
+	}
+}
+
+// queueUpdateDraw runs fn on the UI goroutine for a background goroutine.
+// Once the dashboard closes nothing runs queued updates anymore, so they are
+// dropped instead of waiting forever.
+func (d *dashboard) queueUpdateDraw(fn func()) {
+	d.mu.Lock()
+	select {
+	case <-d.done:
+		d.mu.Unlock()
+		return
+	default:
+	}
+	d.pending.Add(1)
+	d.mu.Unlock()
+
+	defer d.pending.Done()
+	d.app.QueueUpdateDraw(fn)
+}
+
+// quit closes the dashboard and stops the application once the updates
+// already queued have run.
+func (d *dashboard) quit() {
+	d.close()
+	go func() {
+		d.pending.Wait()
+		d.app.Stop()
+	}()
+}
+
+// close stops the informers, log stream and port-forward. It is safe to call
+// more than once.
+func (d *dashboard) close() {
+	d.mu.Lock()
+	select {
+	case <-d.done:
+		d.mu.Unlock()
+		return
+	default:
+	}
+	close(d.done)
+	d.mu.Unlock()
+
+	if d.contextStop != nil {
+		close(d.contextStop)
+	}
+	if d.resourceStop != nil {
+		close(d.resourceStop)
+	}
+	if d.forwardStop != nil {
+		close(d.forwardStop)
+	}
+	if d.stopLogs != nil {
+		d.stopLogs()
+	}
+}
+
+func (d *dashboard) refreshHeader() {
+	namespace := d.namespace
+	if namespace == metav1.NamespaceAll {
+		namespace = "(all)"
+	}
+	d.header.SetText(fmt.Sprintf(" Context: %s   Namespace: %s   Resource: %s", d.context, namespace, uiKinds[d.kind].Name))
+}
+
+func (d *dashboard) refreshContexts() {
+	d.contextNames = SortedContextNames(d.config)
+	d.contexts.Clear()
+	for i, name := range d.contextNames {
+		if name == d.context {
+			d.contexts.AddItem("* "+name, "", 0, nil)
+			d.contexts.SetCurrentItem(i)
+		} else {
+			d.contexts.AddItem("  "+name, "", 0, nil)
+		}
+	}
+}
+
+func (d *dashboard) refreshNamespaces() {
+	if d.nsInformer == nil {
+		return
+	}
+	names := d.nsInformer.GetStore().ListKeys()
+	found := d.namespace == metav1.NamespaceAll
+	for _, name := range names {
+		found = found || name == d.namespace
+	}
+	if !found {
+		// Not allowed to list namespaces, still show the one we are in
+		names = append(names, d.namespace)
+	}
+	sort.Strings(names)
+	names = append([]string{metav1.NamespaceAll}, names...)
+
+	current := d.namespaces.GetCurrentItem()
+	selected := d.namespace
+	if current < len(d.namespaceNames) {
+		selected = d.namespaceNames[current]
+	}
+
+	d.namespaceNames = names
+	d.namespaces.Clear()
+	for i, name := range names {
+		label := name
+		if name == metav1.NamespaceAll {
+			label = "(all namespaces)"
+		}
+		if name == d.namespace {
+			label = "* " + label
+		} else {
+			label = "  " + label
+		}
+		d.namespaces.AddItem(label, "", 0, nil)
+		if name == selected {
+			d.namespaces.SetCurrentItem(i)
+		}
+	}
+}
+
+func (d *dashboard) refreshResources() {
+	if d.informer == nil {
+		return
+	}
+	var objects []runtime.Object
+	for _, item := range d.informer.GetStore().List() {
+		object := item.(runtime.Object)
+		if d.drill == nil || d.drill.matches(object) {
+			objects = append(objects, object)
+		}
+	}
+	sort.Slice(objects, func(i, j int) bool {
+		a, _ := cache.MetaNamespaceKeyFunc(objects[i])
+		b, _ := cache.MetaNamespaceKeyFunc(objects[j])
+		return a < b
+	})
+
+	table := uiKinds[d.kind].Table(objects)
+	table.AllNamespaces = d.namespace == metav1.NamespaceAll
+	// -o wide is the only output option the dashboard takes
+	var printer TablePrinter
+	headers, rows, err := printer.cells(table)
+	if err != nil {
+		d.setError(err)
+		return
+	}
+
+	// Keep the cursor on the same object when rows come and go
+	selectedRow := 1
+	if row := d.selectedRow(); row != nil {
+		for i := range table.Rows {
+			if table.Rows[i].Namespace == row.Namespace && table.Rows[i].Name == row.Name {
+				selectedRow = i + 1
+			}
+		}
+	}
+
+	d.resources.Clear()
+	for c, header := range headers {
+		d.resources.SetCell(0, c, tview.NewTableCell(strings.ToUpper(header)).SetSelectable(false).SetAttributes(tcell.AttrBold))
+	}
+	for r, cells := range rows {
+		for c, cell := range cells {
+			d.resources.SetCell(r+1, c, tview.NewTableCell(tview.Escape(cell)))
+		}
+	}
+	d.table = table
+	d.resources.Select(selectedRow, 0)
+
+	title := uiKinds[d.kind].Name
+	if d.drill != nil {
+		title = fmt.Sprintf("%s of %s %s/%s", title, strings.TrimSuffix(uiKinds[d.drill.kind].Name, "s"), d.drill.namespace, d.drill.name)
+	}
+	d.resources.SetTitle(fmt.Sprintf(" %s (%d) ", title, len(table.Rows)))
+}
+
+func (d *dashboard) selectedRow() *Row {
+	row, _ := d.resources.GetSelection()
+	if d.table == nil || row < 1 || row > len(d.table.Rows) {
+		return nil
+	}
+	return &d.table.Rows[row-1]
+}
+
+func (d *dashboard) selectedPod() *corev1.Pod {
+	if row := d.selectedRow(); row != nil {
+		if pod, ok := row.Object.(*corev1.Pod); ok {
+			return pod
+		}
+	}
+	d.setError(fmt.Errorf("select a pod first, press 1 for pods"))
+	return nil
+}
+
+func (d *dashboard) setStatus(format string, args ...interface{}) {
+	d.status.SetTextColor(tcell.ColorGreen).SetText(" " + fmt.Sprintf(format, args...))
+}
+
+func (d *dashboard) setError(err error) {
+	d.status.SetTextColor(tcell.ColorRed).SetText(" " + err.Error())
+}
+
+// switchContext shows contextName and, once it connects, makes it the
+// current context of the kubeconfig like "k8c switch".
+func (d *dashboard) switchContext(contextName string) {
+	if err := d.connect(contextName); err != nil {
+		d.setError(err)
+		return
+	}
+	// The switch report would tear the screen
+	if err := ChangeKubeconfigContext(io.Discard, d.kubeconfig, contextName); err != nil {
+		d.setError(fmt.Errorf("showing %s, but the kubeconfig was not switched: %v", contextName, err))
+		return
+	}
+	if config, err := LoadKubeconfig(d.kubeconfig); err == nil {
+		d.config = config
+		d.refreshContexts()
+	}
+	d.setStatus("Switched to context %s", contextName)
+	d.app.SetFocus(d.resources)
+}
+
+func (d *dashboard) selectNamespace(namespace string) {
+	d.namespace = namespace
+	d.drill = nil
+	d.watchResources()
+	d.refreshNamespaces()
+	d.app.SetFocus(d.resources)
+}
+
+// open drills down from a workload to its pods and from a pod to its logs.
+func (d *dashboard) open() {
+	row := d.selectedRow()
+	if row == nil {
+		return
+	}
+	if d.kind == uiPods {
+		d.logs()
+		return
+	}
+
+	selector, err := podSelector(row.Object)
+	if err != nil {
+		d.setError(err)
+		return
+	}
+	d.drill = &uiDrill{kind: d.kind, name: row.Name, namespace: row.Namespace, selector: selector}
+	d.kind = uiPods
+	d.watchResources()
+}
+
+// back leaves the pods of a workload for the workload list.
+func (d *dashboard) back() {
+	if d.drill == nil {
+		return
+	}
+	d.kind = d.drill.kind
+	d.drill = nil
+	d.watchResources()
+}
+
+func (d *dashboard) describe() {
+	pod := d.selectedPod()
+	if pod == nil {
+		return
+	}
+	var buf bytes.Buffer
+	WritePodDetail(&buf, pod)
+	d.showDetail(fmt.Sprintf(" Describe %s/%s ", pod.Namespace, pod.Name))
+	d.detail.SetText(buf.String()).ScrollToBeginning()
+}
+
+// logs follows the logs of the selected pod's default container.
+func (d *dashboard) logs() {
+	pod := d.selectedPod()
+	if pod == nil {
+		return
+	}
+	container := defaultContainer(pod)
+	d.showDetail(fmt.Sprintf(" Logs %s/%s [%s] ", pod.Namespace, pod.Name, container))
+	d.detail.ScrollToEnd()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	d.stopLogs = cancel
+	tail := int64(UILogTail)
+	options := corev1.PodLogOptions{Follow: true, TailLines: &tail}
+	sources := []LogSource{{Namespace: pod.Namespace, Pod: pod.Name, Container: container}}
+	clientset := d.clientset
+	out := &contextWriter{ctx: ctx, w: d.detail}
+	go func() {
+		if err := StreamLogs(ctx, clientset, sources, options, out); err != nil {
+			fmt.Fprintf(out, "\n> %v\n", err)
+		}
+	}()
+}
+
+func (d *dashboard) showDetail(title string) {
+	d.detail.Clear().SetTitle(tview.Escape(title))
+	d.pages.SwitchToPage("detail")
+}
+
+func (d *dashboard) closeDetail() {
+	if d.stopLogs != nil {
+		d.stopLogs()
+		d.stopLogs = nil
+	}
+	d.pages.SwitchToPage("main")
+	d.app.SetFocus(d.resources)
+}
+
+// forward port-forwards a free local port to the first port the selected
+// pod declares, until stopped or replaced by another one.
+func (d *dashboard) forward() {
+	pod := d.selectedPod()
+	if pod == nil {
+		return
+	}
+	var remote int32
+	for _, container := range pod.Spec.Containers {
+		if len(container.Ports) > 0 {
+			remote = container.Ports[0].ContainerPort
+			break
+		}
+	}
+	if remote == 0 {
+		d.setError(fmt.Errorf("pod %s declares no container ports", pod.Name))
+		return
+	}
+	local, err := GetFreePort()
+	if err != nil {
+		d.setError(err)
+		return
+	}
+
+	req := d.clientset.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(pod.Namespace).
+		Name(pod.Name).
+		SubResource("portforward")
+	transport, upgrader, err := spdy.RoundTripperFor(d.restConfig)
+	if err != nil {
+		d.setError(err)
+		return
+	}
+	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, http.MethodPost, req.URL())
+
+	stop := make(chan struct{})
+	forwarder, err := portforward.New(dialer, []string{fmt.Sprintf("%d:%d", local, remote)}, stop, make(chan struct{}), io.Discard, io.Discard)
+	if err != nil {
+		d.setError(err)
+		return
+	}
+	if d.forwardStop != nil {
+		close(d.forwardStop)
+	}
+	d.forwardStop = stop
+	go func() {
+		if err := forwarder.ForwardPorts(); err != nil {
+			d.queueUpdateDraw(func() {
+				if d.forwardStop == stop {
+					d.forwardStop = nil
+					d.setError(fmt.Errorf("port-forward %s: %v", pod.Name, err))
+				}
+			})
+		}
+	}()
+	d.setStatus("Forwarding localhost:%d -> %s/%s:%d, P stops it", local, pod.Namespace, pod.Name, remote)
+}
+
+func (d *dashboard) stopForward() {
+	if d.forwardStop == nil {
+		return
+	}
+	close(d.forwardStop)
+	d.forwardStop = nil
+	d.setStatus("Port-forward stopped")
+}
+
+// podSelector returns the selector of the pods object manages.
+func podSelector(object interface{}) (labels.Selector, error) {
+	var selector *metav1.LabelSelector
+	switch object := object.(type) {
+	case *appsv1.Deployment:
+		selector = object.Spec.Selector
+	case *appsv1.StatefulSet:
+		selector = object.Spec.Selector
+	case *appsv1.DaemonSet:
+		selector = object.Spec.Selector
+	case *batchv1.Job:
+		selector = object.Spec.Selector
+	case *corev1.Service:
+		if len(object.Spec.Selector) == 0 {
+			return nil, fmt.Errorf("service %s has no selector", object.Name)
+		}
+		return labels.SelectorFromSet(object.Spec.Selector), nil
+	}
+	if selector == nil {
+		return nil, fmt.Errorf("no pods to show")
+	}
+	return metav1.LabelSelectorAsSelector(selector)
+}
+
+// contextWriter drops writes once ctx is done, so a log stream that was
+// left doesn't write into the next view.
+type contextWriter struct {
+	ctx context.Context
+	w   io.Writer
+}
+
+func (w *contextWriter) Write(p []byte) (int, error) {
+	if err := w.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return w.w.Write(p)
+}
@@ -0,0 +1,108 @@
+package features
+
+import (
+	"context"
+	"reflect"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+	watchtools "k8s.io/client-go/tools/watch"
+)
+
+// listObject is a list type like *corev1.PodList.
+type listObject interface {
+	metav1.ListInterface
+	runtime.Object
+}
+
+// listWatcher is what the typed clients and the dynamic client have in
+// common.
+type listWatcher[T listObject] interface {
+	List(ctx context.Context, options metav1.ListOptions) (T, error)
+	Watch(ctx context.Context, options metav1.ListOptions) (watch.Interface, error)
+}
+
+// listAndWatch lists client page by page and hands emit the table built from
+// each page. With options.Watch it then watches from where the list left off
+// and emits a table for every change.
+func listAndWatch[T listObject](ctx context.Context, client listWatcher[T], options metav1.ListOptions, table func(T) *Table, emit func(*Table) error) error {
+	resourceVersion := ""
+	err := listPages(ctx, client.List, options, func(page T) error {
+		resourceVersion = page.GetResourceVersion()
+		return emit(watchEvent(table(page), options, watch.Added))
+	})
+	if err != nil {
+		return err
+	}
+	return watchTables(ctx, client, resourceVersion, options, table, emit)
+}
+
+// watchTables emits a table for every event on client after resourceVersion
+// until ctx is cancelled. The rows carry the event type. Watches the server
+// closes are reopened where they stopped.
+func watchTables[T listObject](ctx context.Context, client listWatcher[T], resourceVersion string, options metav1.ListOptions, table func(T) *Table, emit func(*Table) error) error {
+	if !options.Watch {
+		return nil
+	}
+
+	watcher, err := watchtools.NewRetryWatcherWithContext(ctx, resourceVersion, &cache.ListWatch{
+		WatchFuncWithContext: func(ctx context.Context, watchOptions metav1.ListOptions) (watch.Interface, error) {
+			// The retry watcher only sets the resource version
+			watchOptions.LabelSelector = options.LabelSelector
+			watchOptions.FieldSelector = options.FieldSelector
+			return client.Watch(ctx, watchOptions)
+		},
+	})
+	if err != nil {
+		return err
+	}
+	defer watcher.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.ResultChan():
+			if !ok {
+				return nil
+			}
+			switch event.Type {
+			case watch.Added, watch.Modified, watch.Deleted:
+			case watch.Error:
+				return apierrors.FromObject(event.Object)
+			default:
+				continue
+			}
+
+			// The table builders take lists, wrap the object in one
+			list := newList[T]()
+			if err := meta.SetList(list, []runtime.Object{event.Object}); err != nil {
+				return err
+			}
+			if err := emit(watchEvent(table(list), options, event.Type)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// newList returns an empty list of type T.
+func newList[T listObject]() T {
+	var list T
+	return reflect.New(reflect.TypeOf(list).Elem()).Interface().(T)
+}
+
+// watchEvent marks the rows of table with the event type when watching.
+// Listed rows count as added, like kubectl shows them.
+func watchEvent(table *Table, options metav1.ListOptions, eventType watch.EventType) *Table {
+	if options.Watch {
+		for i := range table.Rows {
+			table.Rows[i].Event = string(eventType)
+		}
+	}
+	return table
+}
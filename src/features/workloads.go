@@ -0,0 +1,245 @@
+package features
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func StatefulSetTable(statefulSets *appsv1.StatefulSetList) *Table {
+	table := NewTable("statefulset.apps",
+		Column{Header: "NAME"},
+		Column{Header: "DESIRED"},
+		Column{Header: "CURRENT"},
+		Column{Header: "READY"},
+		Column{Header: "AGE"},
+		Column{Header: "CONTAINERS", Wide: true},
+		Column{Header: "IMAGES", Wide: true},
+	)
+
+	for i := range statefulSets.Items {
+		sts := &statefulSets.Items[i]
+		containers, images := podTemplateContainers(sts.Spec.Template.Spec)
+
+		table.Append(sts.Name, sts,
+			sts.Name,
+			fmt.Sprintf("%d", replicasOrOne(sts.Spec.Replicas)),
+			fmt.Sprintf("%d", sts.Status.Replicas),
+			fmt.Sprintf("%d", sts.Status.ReadyReplicas),
+			HumanReadableDuration(time.Since(sts.CreationTimestamp.Time)),
+			containers,
+			images,
+		)
+	}
+	return table
+}
+
+func DaemonSetTable(daemonSets *appsv1.DaemonSetList) *Table {
+	table := NewTable("daemonset.apps",
+		Column{Header: "NAME"},
+		Column{Header: "DESIRED"},
+		Column{Header: "CURRENT"},
+		Column{Header: "READY"},
+		Column{Header: "UP-TO-DATE"},
+		Column{Header: "AVAILABLE"},
+		Column{Header: "NODE SELECTOR"},
+		Column{Header: "AGE"},
+		Column{Header: "CONTAINERS", Wide: true},
+		Column{Header: "IMAGES", Wide: true},
+		Column{Header: "SELECTOR", Wide: true},
+	)
+
+	for i := range daemonSets.Items {
+		ds := &daemonSets.Items[i]
+		containers, images := podTemplateContainers(ds.Spec.Template.Spec)
+
+		table.Append(ds.Name, ds,
+			ds.Name,
+			fmt.Sprintf("%d", ds.Status.DesiredNumberScheduled),
+			fmt.Sprintf("%d", ds.Status.CurrentNumberScheduled),
+			fmt.Sprintf("%d", ds.Status.NumberReady),
+			fmt.Sprintf("%d", ds.Status.UpdatedNumberScheduled),
+			fmt.Sprintf("%d", ds.Status.NumberAvailable),
+			formatLabels(ds.Spec.Template.Spec.NodeSelector),
+			HumanReadableDuration(time.Since(ds.CreationTimestamp.Time)),
+			containers,
+			images,
+			formatSelector(ds.Spec.Selector),
+		)
+	}
+	return table
+}
+
+func ReplicaSetTable(replicaSets *appsv1.ReplicaSetList) *Table {
+	table := NewTable("replicaset.apps",
+		Column{Header: "NAME"},
+		Column{Header: "DESIRED"},
+		Column{Header: "CURRENT"},
+		Column{Header: "READY"},
+		Column{Header: "AGE"},
+		Column{Header: "CONTAINERS", Wide: true},
+		Column{Header: "IMAGES", Wide: true},
+		Column{Header: "SELECTOR", Wide: true},
+	)
+
+	for i := range replicaSets.Items {
+		rs := &replicaSets.Items[i]
+		containers, images := podTemplateContainers(rs.Spec.Template.Spec)
+
+		table.Append(rs.Name, rs,
+			rs.Name,
+			fmt.Sprintf("%d", replicasOrOne(rs.Spec.Replicas)),
+			fmt.Sprintf("%d", rs.Status.Replicas),
+			fmt.Sprintf("%d", rs.Status.ReadyReplicas),
+			HumanReadableDuration(time.Since(rs.CreationTimestamp.Time)),
+			containers,
+			images,
+			formatSelector(rs.Spec.Selector),
+		)
+	}
+	return table
+}
+
+func JobTable(jobs *batchv1.JobList) *Table {
+	table := NewTable("job.batch",
+		Column{Header: "NAME"},
+		Column{Header: "STATUS"},
+		Column{Header: "COMPLETIONS"},
+		Column{Header: "DURATION"},
+		Column{Header: "AGE"},
+		Column{Header: "CONTAINERS", Wide: true},
+		Column{Header: "IMAGES", Wide: true},
+		Column{Header: "SELECTOR", Wide: true},
+	)
+
+	for i := range jobs.Items {
+		job := &jobs.Items[i]
+		containers, images := podTemplateContainers(job.Spec.Template.Spec)
+
+		table.Append(job.Name, job,
+			job.Name,
+			JobStatus(job),
+			JobCompletions(job),
+			JobDuration(job),
+			HumanReadableDuration(time.Since(job.CreationTimestamp.Time)),
+			containers,
+			images,
+			formatSelector(job.Spec.Selector),
+		)
+	}
+	return table
+}
+
+func CronJobTable(cronJobs *batchv1.CronJobList) *Table {
+	table := NewTable("cronjob.batch",
+		Column{Header: "NAME"},
+		Column{Header: "SCHEDULE"},
+		Column{Header: "SUSPEND"},
+		Column{Header: "ACTIVE"},
+		Column{Header: "LAST SCHEDULE"},
+		Column{Header: "AGE"},
+		Column{Header: "TIMEZONE", Wide: true},
+		Column{Header: "CONTAINERS", Wide: true},
+		Column{Header: "IMAGES", Wide: true},
+	)
+
+	for i := range cronJobs.Items {
+		cj := &cronJobs.Items[i]
+		containers, images := podTemplateContainers(cj.Spec.JobTemplate.Spec.Template.Spec)
+
+		suspend := "False"
+		if cj.Spec.Suspend != nil && *cj.Spec.Suspend {
+			suspend = "True"
+		}
+		lastSchedule := "<none>"
+		if cj.Status.LastScheduleTime != nil {
+			lastSchedule = HumanReadableDuration(time.Since(cj.Status.LastScheduleTime.Time))
+		}
+		timeZone := "<none>"
+		if cj.Spec.TimeZone != nil {
+			timeZone = *cj.Spec.TimeZone
+		}
+
+		table.Append(cj.Name, cj,
+			cj.Name,
+			cj.Spec.Schedule,
+			suspend,
+			fmt.Sprintf("%d", len(cj.Status.Active)),
+			lastSchedule,
+			HumanReadableDuration(time.Since(cj.CreationTimestamp.Time)),
+			timeZone,
+			containers,
+			images,
+		)
+	}
+	return table
+}
+
+// JobStatus returns Complete, Failed, Suspended or Running from the job
+// conditions.
+func JobStatus(job *batchv1.Job) string {
+	for _, condition := range job.Status.Conditions {
+		if condition.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch condition.Type {
+		case batchv1.JobComplete:
+			return "Complete"
+		case batchv1.JobFailed:
+			return "Failed"
+		case batchv1.JobSuspended:
+			return "Suspended"
+		}
+	}
+	return "Running"
+}
+
+func JobCompletions(job *batchv1.Job) string {
+	if job.Spec.Completions != nil {
+		return fmt.Sprintf("%d/%d", job.Status.Succeeded, *job.Spec.Completions)
+	}
+	// Without completions the job is done once any pod succeeds
+	if job.Spec.Parallelism != nil && *job.Spec.Parallelism > 1 {
+		return fmt.Sprintf("%d/1 of %d", job.Status.Succeeded, *job.Spec.Parallelism)
+	}
+	return fmt.Sprintf("%d/1", job.Status.Succeeded)
+}
+
+func JobDuration(job *batchv1.Job) string {
+	if job.Status.StartTime == nil {
+		return "<none>"
+	}
+	end := time.Now()
+	if job.Status.CompletionTime != nil {
+		end = job.Status.CompletionTime.Time
+	}
+	return HumanReadableDuration(end.Sub(job.Status.StartTime.Time))
+}
+
+func podTemplateContainers(spec corev1.PodSpec) (string, string) {
+	var containers, images []string
+	for _, container := range spec.Containers {
+		containers = append(containers, container.Name)
+		images = append(images, container.Image)
+	}
+	return strings.Join(containers, ","), strings.Join(images, ",")
+}
+
+func replicasOrOne(replicas *int32) int32 {
+	if replicas == nil {
+		return 1
+	}
+	return *replicas
+}
+
+func formatSelector(selector *metav1.LabelSelector) string {
+	if selector == nil {
+		return "<none>"
+	}
+	return metav1.FormatLabelSelector(selector)
+}
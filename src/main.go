@@ -2,9 +2,11 @@ package main
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/devopscorner/k8s-context/src/features"
 	"github.com/muesli/termenv"
+	"golang.org/x/term"
 )
 
 func main() {
@@ -12,10 +14,13 @@ func main() {
 	authorStyle := termenv.Style{}.Foreground(termenv.ANSIBlue)
 	appNameStyle := termenv.Style{}.Foreground(termenv.ANSIWhite).Bold()
 
-	fmt.Print(logoStyle.Styled(features.Logo))
-	fmt.Println(authorStyle.Styled(features.Author))
-	fmt.Println("===================================")
-	fmt.Println("[[ ", appNameStyle.Styled(features.AppName), " ]] -", features.VERSION)
-	fmt.Println("===================================")
+	// Keep piped output clean for eval and scripts
+	if term.IsTerminal(int(os.Stdout.Fd())) {
+		fmt.Print(logoStyle.Styled(features.Logo))
+		fmt.Println(authorStyle.Styled(features.Author))
+		fmt.Println("===================================")
+		fmt.Println("[[ ", appNameStyle.Styled(features.AppName), " ]] -", features.VERSION)
+		fmt.Println("===================================")
+	}
 	features.GetCommands()
 }